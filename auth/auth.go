@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// The request does not have a valid session.
+var ErrUnauthenticated = errors.New("the session is invalid or expired")
+
+// The session can not be verified now, such as the token manager is down.
+var ErrUnavailable = errors.New("the session verifier is unavailable")
+
+// Name of the session token cookie.
+const sessionCookieName string = "session_token"
+
+// Authenticator verifies the user of the request.
+type Authenticator interface {
+	// Returns the user id of the request.
+	// Returns ErrUnauthenticated if the request does not have a valid session.
+	// The other errors, such as ErrUnavailable, are not the fault of the client.
+	Authenticate(ctx context.Context, r *http.Request) (string, error)
+}
+
+type contextKey struct{}
+
+// Returns the context with the user id.
+func WithUserId(ctx context.Context, userId string) context.Context {
+	return context.WithValue(ctx, contextKey{}, userId)
+}
+
+// Returns the user id in the context.
+// Returns empty if the request has not been authenticated.
+func UserId(ctx context.Context) string {
+	userId, _ := ctx.Value(contextKey{}).(string)
+	return userId
+}
+
+// Returns the session token in `Authorization: Bearer` header or the session cookie.
+func SessionToken(r *http.Request) (string, bool) {
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		token := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		return token, len(token) != 0
+	}
+
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil || len(cookie.Value) == 0 {
+		return "", false
+	}
+	return cookie.Value, true
+}
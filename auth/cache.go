@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache of the verified session tokens.
+// The key is the hash of the token so that the cache does not hold the tokens.
+// The least recently used token is removed when the cache is full.
+type TokenCache struct {
+	mutex   sync.Mutex
+	maxSize int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	// The front is the most recently used.
+	order *list.List
+	// Incremented on every revocation.
+	// The token verified across a revocation is not cached because it may be the revoked one.
+	generation uint64
+}
+
+type tokenCacheEntry struct {
+	key     string
+	userId  string
+	expires time.Time
+}
+
+// Create token cache.
+//
+// Arguments:
+// - maxSize: max number of the cached tokens.
+// - ttl: max period to keep the token. It is shortened to the expiration of the token.
+func NewTokenCache(maxSize int, ttl time.Duration) *TokenCache {
+	return &TokenCache{
+		maxSize: maxSize,
+		ttl:     ttl,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Returns the user id of the cached token.
+func (c *TokenCache) get(token string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[tokenKey(token)]
+	if !ok {
+		return "", false
+	}
+	entry := element.Value.(*tokenCacheEntry)
+	if !time.Now().Before(entry.expires) {
+		c.removeElement(element)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return entry.userId, true
+}
+
+// Returns the current generation. It is passed to add after the verification.
+func (c *TokenCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// Cache the verified token.
+// It is not cached if a token was revoked after generation, or the token has expired.
+func (c *TokenCache) add(token string, userId string, generation uint64) {
+	expires := time.Now().Add(c.ttl)
+	if tokenExpires, ok := tokenExpiration(token); ok && tokenExpires.Before(expires) {
+		expires = tokenExpires
+	}
+	if !time.Now().Before(expires) {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.generation != generation {
+		return
+	}
+	key := tokenKey(token)
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+	for c.order.Len() >= c.maxSize && c.order.Len() != 0 {
+		c.removeElement(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(&tokenCacheEntry{
+		key:     key,
+		userId:  userId,
+		expires: expires,
+	})
+}
+
+// Remove the token from the cache.
+func (c *TokenCache) RevokeToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	if element, ok := c.entries[tokenKey(token)]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Remove all tokens of the user from the cache.
+func (c *TokenCache) RevokeUser(userId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*tokenCacheEntry).userId == userId {
+			c.removeElement(element)
+		}
+		element = next
+	}
+}
+
+func (c *TokenCache) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*tokenCacheEntry).key)
+}
+
+// Returns the cache key of the token.
+func tokenKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Returns the `exp` claim if the token is a JWT.
+// The signature is not verified here because the token manager has verified the token.
+func tokenExpiration(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// Authenticator that caches the users verified by the other authenticator.
+type CachedAuthenticator struct {
+	authenticator Authenticator
+	cache         *TokenCache
+}
+
+// Create authenticator that verifies the token by authenticator only if it is not in the cache.
+func NewCachedAuthenticator(authenticator Authenticator, cache *TokenCache) *CachedAuthenticator {
+	return &CachedAuthenticator{
+		authenticator: authenticator,
+		cache:         cache,
+	}
+}
+
+func (a *CachedAuthenticator) Authenticate(ctx context.Context, r *http.Request) (string, error) {
+	sessionToken, ok := SessionToken(r)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	if userId, ok := a.cache.get(sessionToken); ok {
+		return userId, nil
+	}
+
+	generation := a.cache.currentGeneration()
+	userId, err := a.authenticator.Authenticate(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	a.cache.add(sessionToken, userId, generation)
+	return userId, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/dapr/go-sdk/client"
+	"github.com/hello-slide/slide-manager/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator that verifies the session token by the token manager app via Dapr.
+type DaprAuthenticator struct {
+	client           client.Client
+	tokenManagerName string
+}
+
+// Create authenticator with the Dapr client.
+//
+// Arguments:
+// - client: Dapr client.
+// - tokenManagerName: Dapr app name of the token manager.
+func NewDaprAuthenticator(client client.Client, tokenManagerName string) *DaprAuthenticator {
+	return &DaprAuthenticator{
+		client:           client,
+		tokenManagerName: tokenManagerName,
+	}
+}
+
+func (a *DaprAuthenticator) Authenticate(ctx context.Context, r *http.Request) (string, error) {
+	sessionToken, ok := SessionToken(r)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	userId, err := utils.VerifySessionToken(ctx, a.client, sessionToken, a.tokenManagerName)
+	if err != nil {
+		return "", verifyError(err)
+	}
+	if len(userId) == 0 {
+		return "", ErrUnauthenticated
+	}
+	return userId, nil
+}
+
+// Convert the error of the token verification.
+// The token manager rejects the invalid token with 4xx, and Dapr returns it as the gRPC status of the http status.
+// The other errors are of the sidecar or the token manager, so the token is not treated as invalid.
+func verifyError(err error) error {
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument:
+			return ErrUnauthenticated
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+	}
+	return fmt.Errorf("failed to verify the session token: %w", err)
+}
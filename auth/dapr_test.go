@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerifyError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "rejected", err: status.Error(codes.Unauthenticated, "invalid token"), want: ErrUnauthenticated},
+		{name: "forbidden", err: status.Error(codes.PermissionDenied, "expired"), want: ErrUnauthenticated},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "connection refused"), want: ErrUnavailable},
+		{name: "timeout", err: status.Error(codes.DeadlineExceeded, "timeout"), want: ErrUnavailable},
+		{name: "internal", err: status.Error(codes.Internal, "panic"), want: nil},
+		{name: "not grpc", err: errors.New("nil request"), want: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := verifyError(c.err)
+			if c.want != nil && !errors.Is(err, c.want) {
+				t.Errorf("got %v, want %v", err, c.want)
+			}
+			// The errors that are not rejections must not be taken as an invalid session.
+			if c.want != ErrUnauthenticated && errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("got %v, want not ErrUnauthenticated", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// Authenticator that treats every request as the same user.
+// It is for the tests and the local development without the token manager.
+type StaticAuthenticator struct {
+	userId string
+}
+
+// Create authenticator that returns userId for every request.
+func NewStaticAuthenticator(userId string) *StaticAuthenticator {
+	return &StaticAuthenticator{
+		userId: userId,
+	}
+}
+
+func (a *StaticAuthenticator) Authenticate(ctx context.Context, r *http.Request) (string, error) {
+	return a.userId, nil
+}
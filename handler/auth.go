@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+// Verify the user of the request and put the user id in the request context.
+// The handler reads it by auth.UserId(r.Context()).
+func RequireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := authenticator.Authenticate(r.Context(), r)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			unauthenticated(w, r, err)
+			return
+		}
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+
+		next(w, r.WithContext(auth.WithUserId(r.Context(), userId)))
+	}
+}
+
+// Respond to the request without a valid session.
+// The browser is redirected to /account/update to update the session token,
+// and the API clients get 401 because they can not follow it.
+func unauthenticated(w http.ResponseWriter, r *http.Request, err error) {
+	if acceptsHTML(r) {
+		redirectUrl := strings.Join([]string{url, "/account/update?redirect=", r.URL.Path}, "")
+		http.Redirect(w, r, redirectUrl, http.StatusTemporaryRedirect)
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="hello-slide"`)
+	writeError(w, http.StatusUnauthorized, "unauthenticated", err)
+}
+
+// Returns true if the client accepts html, such as the page navigation of the browser.
+func acceptsHTML(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == "text/html" {
+			return true
+		}
+	}
+	return false
+}
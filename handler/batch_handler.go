@@ -0,0 +1,5 @@
+package handler
+
+// Apply the operations to a slide in one request.
+// The operations can not be sent as the header values, so it takes the same json body as the v2 API.
+var BatchHandler = v2Handler(func() v2Request { return &BatchRequest{} })
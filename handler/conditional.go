@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Response with the validators of the conditional request.
+type conditionalResponse struct {
+	response interface{}
+	// ETag without quotes.
+	etag string
+	// Not sent if zero.
+	lastModified time.Time
+}
+
+// Set ETag and Last-Modified, and write 304 Not Modified if the client has the current one.
+// If-Modified-Since is used only without If-None-Match.
+//
+// Arguments:
+// - w: http writer.
+// - r: http requests.
+// - etag: ETag without quotes.
+// - lastModified: last modified time. Not sent if zero.
+//
+// Return:
+// - bool: true if 304 has been written.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	setValidators(w, etag, lastModified)
+
+	notModified := false
+	if ifNoneMatch := r.Header.Get("If-None-Match"); len(ifNoneMatch) != 0 {
+		for _, tag := range parseETags(ifNoneMatch) {
+			if tag == "*" || tag == etag {
+				notModified = true
+				break
+			}
+		}
+	} else if ifModifiedSince := r.Header.Get("If-Modified-Since"); len(ifModifiedSince) != 0 && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		notModified = err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// Set ETag and Last-Modified.
+// Last-Modified is not sent if it is zero.
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", quoteETag(etag))
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Returns the ETag in If-Match without quotes.
+// Returns empty if it is not specified or `*` because the page always exists.
+// Only the first one is used if there are multiple ETags.
+func ifMatchETag(r *http.Request) string {
+	tags := parseETags(r.Header.Get("If-Match"))
+	if len(tags) == 0 || tags[0] == "*" {
+		return ""
+	}
+	return tags[0]
+}
+
+// Returns the ETags in the header without quotes and the weak prefix.
+func parseETags(header string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		tag = strings.Trim(tag, `"`)
+		if len(tag) != 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func quoteETag(etag string) string {
+	return `"` + etag + `"`
+}
@@ -6,8 +6,7 @@ import (
 	"net/http"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
 func CreateHandler(w http.ResponseWriter, r *http.Request) {
@@ -25,19 +24,12 @@ func CreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/create")
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
-		return
-	}
-	if len(userId) == 0 {
-		return
-	}
+	userId := auth.UserId(r.Context())
 
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
+	slideManager := newSlideManager(ctx, userId)
 	slideId, err := slideManager.Create(title)
 	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+		errorResponse(w, err)
 		return
 	}
 
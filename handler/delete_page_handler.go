@@ -5,9 +5,7 @@ import (
 	"net/http"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	_storage "github.com/hello-slide/slide-manager/storage"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
 func DeletePageHandler(w http.ResponseWriter, r *http.Request) {
@@ -30,24 +28,16 @@ func DeletePageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/deletepage")
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
-		return
-	}
-	if len(userId) == 0 {
-		return
-	}
+	userId := auth.UserId(r.Context())
 
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
-	storageClient, err := _storage.CreateClient(ctx)
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
 	}
-	storageOp := _storage.NewStorageOp(ctx, *storageClient, "page-data")
-	if err := slideManager.DeletePage(slideId, pageId, *storageOp); err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+	if err := slideManager.DeletePage(slideId, pageId, storageOp); err != nil {
+		errorResponse(w, err)
 		return
 	}
 }
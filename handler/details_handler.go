@@ -6,8 +6,7 @@ import (
 	"net/http"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
 func DetailsHandler(w http.ResponseWriter, r *http.Request) {
@@ -24,19 +23,15 @@ func DetailsHandler(w http.ResponseWriter, r *http.Request) {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
 	}
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/details")
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	slideDetails, etag, err := slideManager.GetSlideDetailsWithETag(slideId)
 	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
-		return
-	}
-	if len(userId) == 0 {
+		errorResponse(w, err)
 		return
 	}
-
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
-	slideDetails, err := slideManager.GetSlideDetails(slideId)
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+	if checkNotModified(w, r, etag, slideDetails.ChangeTime()) {
 		return
 	}
 
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+func DuplicateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headerData, err := networkUtils.GetHeader(w, r)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	slideId, err := networkUtils.PickValue("SlideID", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	title, err := networkUtils.PickValue("Title", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	newSlideId, err := slideManager.Duplicate(slideId, title, storageOp)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	tokenJson, err := json.Marshal(map[string]string{
+		"slide_id": newSlideId,
+	})
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(tokenJson)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+func EmptyTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	if err := slideManager.EmptyTrash(storageOp); err != nil {
+		errorResponse(w, err)
+		return
+	}
+}
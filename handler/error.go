@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/hello-slide/slide-manager/auth"
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+type errorBody struct {
+	StatusCode int `json:"status_code"`
+	// Machine-readable error code such as `slide_not_found`.
+	Code   string `json:"code"`
+	Status string `json:"status"`
+}
+
+// http status and error code of the slide errors.
+var errorCodes = []struct {
+	err        error
+	httpStatus int
+	code       string
+}{
+	{slide.ErrSlideNotFound, http.StatusNotFound, "slide_not_found"},
+	{slide.ErrPageNotFound, http.StatusNotFound, "page_not_found"},
+	{slide.ErrRevisionNotFound, http.StatusNotFound, "revision_not_found"},
+	{slide.ErrTrashItemNotFound, http.StatusNotFound, "trash_item_not_found"},
+	{slide.ErrGrantNotFound, http.StatusNotFound, "grant_not_found"},
+	{slide.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{slide.ErrLinkNotFound, http.StatusNotFound, "link_not_found"},
+	{slide.ErrLinkPassword, http.StatusUnauthorized, "invalid_password"},
+	{slide.ErrIndexOutOfRange, http.StatusBadRequest, "index_out_of_range"},
+	{slide.ErrInvalidInput, http.StatusBadRequest, "invalid_input"},
+	{slide.ErrConflict, http.StatusConflict, "conflict"},
+	{slide.ErrQuotaExceeded, http.StatusRequestEntityTooLarge, "quota_exceeded"},
+	{slide.ErrPageTooLarge, http.StatusRequestEntityTooLarge, "page_too_large"},
+	{slide.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{auth.ErrUnavailable, http.StatusServiceUnavailable, "auth_unavailable"},
+}
+
+// Send error response.
+// The slide errors are mapped to 404, 400, 401, 403, 409, 412 and 413,
+// the unavailable session verifier is 503, and the others are 500.
+func errorResponse(w http.ResponseWriter, err error) {
+	httpStatus, code := errorStatus(err)
+	writeError(w, httpStatus, code, err)
+}
+
+// Returns the http status and the error code of the error.
+func errorStatus(err error) (int, string) {
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			return errorCode.httpStatus, errorCode.code
+		}
+	}
+	return http.StatusInternalServerError, "internal_error"
+}
+
+// Write error json with http status.
+//
+// Arguments:
+// - w: http writer.
+// - httpStatus: http status.
+// - code: machine-readable error code.
+// - err: error.
+func writeError(w http.ResponseWriter, httpStatus int, code string, err error) {
+	body, _ := json.Marshal(errorBody{
+		StatusCode: 1,
+		Code:       code,
+		Status:     err.Error(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(body)
+}
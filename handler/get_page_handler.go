@@ -5,9 +5,7 @@ import (
 	"net/http"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	_storage "github.com/hello-slide/slide-manager/storage"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
 func GetPageHandler(w http.ResponseWriter, r *http.Request) {
@@ -30,28 +28,23 @@ func GetPageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/getpage")
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
 	}
-	if len(userId) == 0 {
-		return
-	}
-
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
-	storageClient, err := _storage.CreateClient(ctx)
+	reader, pageInfo, err := slideManager.OpenPage(slideId, pageId, storageOp)
 	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+		errorResponse(w, err)
 		return
 	}
-	storageOp := _storage.NewStorageOp(ctx, *storageClient, "page-data")
-	data, err := slideManager.GetPage(slideId, pageId, *storageOp)
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+	if checkNotModified(w, r, pageInfo.ETag, pageInfo.ModTime) {
+		reader.Close()
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
-	w.Write(data)
+	writeStream(w, http.StatusOK, &pageStream{reader: reader, info: pageInfo})
 }
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+func GetRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headerData, err := networkUtils.GetHeader(w, r)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	slideId, err := networkUtils.PickValue("SlideID", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	pageId, err := networkUtils.PickValue("PageID", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	revision, err := networkUtils.PickValue("Revision", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	revisionInt, err := strconv.Atoi(revision)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	data, err := slideManager.GetRevision(slideId, pageId, revisionInt, storageOp)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Write(data)
+}
@@ -2,10 +2,18 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"cloud.google.com/go/storage"
 	dapr "github.com/dapr/go-sdk/client"
+	"github.com/hello-slide/slide-manager/auth"
+	"github.com/hello-slide/slide-manager/ratelimit"
+	"github.com/hello-slide/slide-manager/slide"
+	"github.com/hello-slide/slide-manager/state"
 	_storage "github.com/hello-slide/slide-manager/storage"
 )
 
@@ -14,6 +22,80 @@ var storageClient *storage.Client
 var tokenManagerName string = os.Getenv("TOKEN_MANAGER")
 var url string = os.Getenv("API_URL")
 
+// State store backend. `dapr`(default), `memory` or `file`.
+var stateBackend string = os.Getenv("STATE_BACKEND")
+
+// Directory of the BoltDB file of the file state store.
+var stateDir string = os.Getenv("STATE_DIR")
+
+// State store shared by all requests when not using Dapr.
+var localState state.StateStore
+
+// Page data storage backend. `gcs`(default) or `local`.
+var storageBackend string = os.Getenv("STORAGE_BACKEND")
+
+// Directory of the local page data storage.
+var storageDir string = os.Getenv("STORAGE_DIR")
+
+// Authenticator backend. `dapr`(default) or `static`.
+var authBackend string = os.Getenv("AUTH_BACKEND")
+
+// User id of all requests with the static authenticator.
+var authStaticUserId string = os.Getenv("AUTH_STATIC_USER_ID")
+
+// Authenticator of the user requests.
+var authenticator auth.Authenticator
+
+// Max number of the cached session tokens. 10000 if empty, and the cache is disabled if 0.
+var tokenCacheSize string = os.Getenv("TOKEN_CACHE_SIZE")
+
+// Max period to cache the verified session token such as `5m`. 5 minutes if empty.
+var tokenCacheTTL string = os.Getenv("TOKEN_CACHE_TTL")
+
+// Dapr pubsub name of the session revocation events. The events are not subscribed if it or APP_API_TOKEN is empty.
+var tokenPubsubName string = os.Getenv("TOKEN_PUBSUB")
+
+// Topic of the session revocation events. `session-revoked` if empty.
+var tokenRevokedTopic string = os.Getenv("TOKEN_REVOKED_TOPIC")
+
+// Cache of the verified session tokens. nil if the cache is disabled.
+var tokenCache *auth.TokenCache
+
+// Token that Dapr sends in `dapr-api-token` header when invoking this app.
+var appAPIToken string = os.Getenv("APP_API_TOKEN")
+
+// Comma-separated app ids allowed to call the internal API. No app if empty.
+var internalAllowedApps string = os.Getenv("INTERNAL_ALLOWED_APPS")
+
+// Rate limiter backend. `memory`(default), `state` or `off`.
+var rateLimitBackend string = os.Getenv("RATE_LIMIT_BACKEND")
+
+// Requests per second of each user and route. 10 if empty, and unlimited if 0.
+var rateLimitRate string = os.Getenv("RATE_LIMIT_RATE")
+
+// Requests of each user and route allowed at once. 20 if empty.
+var rateLimitBurst string = os.Getenv("RATE_LIMIT_BURST")
+
+// Comma-separated limits of the routes such as `/slide/setpage=2:10`. `<rate>:<burst>` of each route.
+// The REST API is limited by the operation such as `PUT /slides/{slide_id}/pages/{page_id}/data=2:10`.
+var rateLimitRoutes string = os.Getenv("RATE_LIMIT_ROUTES")
+
+// Dapr state store of the rate limiter buckets. `SLIDE_CONFIG` if empty.
+var rateLimitState string = os.Getenv("RATE_LIMIT_STATE")
+
+// Comma-separated addresses or CIDRs of the proxies such as the ingress.
+// The client address is read from X-Forwarded-For only if the peer is one of them. No proxy is trusted if empty.
+var trustedProxies string = os.Getenv("TRUSTED_PROXIES")
+
+// Rate limiter of the user requests. nil if it is off.
+var rateLimiter ratelimit.Limiter
+
+// Interval to purge the expired trash of all users such as `1h`. 1 hour if empty, and disabled if 0.
+var trashPurgeInterval string = os.Getenv("TRASH_PURGE_INTERVAL")
+
+// Bucket name of the page data.
+const pageBucketName string = "page-data"
+
 // Initialize dapr client.
 func InitClient() error {
 	_client, err := dapr.NewClient()
@@ -26,7 +108,16 @@ func InitClient() error {
 }
 
 // Initialize storage client.
+// The Google Cloud Storage client is not created when using the local storage.
 func InitStorage(ctx context.Context) error {
+	switch storageBackend {
+	case "", "gcs":
+	case "local":
+		return nil
+	default:
+		return fmt.Errorf("unknown storage backend: %s", storageBackend)
+	}
+
 	_storageClient, err := _storage.CreateClient(ctx)
 	if err != nil {
 		return err
@@ -35,3 +126,154 @@ func InitStorage(ctx context.Context) error {
 	storageClient = _storageClient
 	return nil
 }
+
+// Initialize state store selected by `STATE_BACKEND`.
+func InitState() error {
+	switch stateBackend {
+	case "", "dapr":
+		return nil
+	case "memory":
+		localState = state.NewMemoryState()
+	case "file":
+		fileState, err := state.NewFileState(stateDir)
+		if err != nil {
+			return err
+		}
+		localState = fileState
+	default:
+		return fmt.Errorf("unknown state backend: %s", stateBackend)
+	}
+	return nil
+}
+
+// Initialize rate limiter selected by `RATE_LIMIT_BACKEND`.
+// It must be called after InitState.
+func InitRateLimit() error {
+	if err := parseRateLimits(); err != nil {
+		return err
+	}
+
+	switch rateLimitBackend {
+	case "", "memory":
+		rateLimiter = ratelimit.NewMemoryLimiter(100000)
+	case "state":
+		if localState != nil {
+			rateLimiter = ratelimit.NewStateLimiter(localState)
+			return nil
+		}
+		store := rateLimitState
+		if len(store) == 0 {
+			store = os.Getenv("SLIDE_CONFIG")
+		}
+		ctx := context.Background()
+		rateLimiter = ratelimit.NewStateLimiter(state.NewState(&client, &ctx, store))
+	case "off":
+		rateLimiter = nil
+	default:
+		return fmt.Errorf("unknown rate limit backend: %s", rateLimitBackend)
+	}
+	return nil
+}
+
+// Initialize authenticator selected by `AUTH_BACKEND`.
+// It must be called after InitClient.
+func InitAuth() error {
+	switch authBackend {
+	case "", "dapr":
+		authenticator = auth.NewDaprAuthenticator(client, tokenManagerName)
+		if err := initTokenCache(); err != nil {
+			return err
+		}
+	case "static":
+		if len(authStaticUserId) == 0 {
+			return fmt.Errorf("AUTH_STATIC_USER_ID is required for the static authenticator")
+		}
+		authenticator = auth.NewStaticAuthenticator(authStaticUserId)
+	default:
+		return fmt.Errorf("unknown auth backend: %s", authBackend)
+	}
+	return nil
+}
+
+// Wrap the authenticator with the token cache configured by `TOKEN_CACHE_SIZE` and `TOKEN_CACHE_TTL`.
+func initTokenCache() error {
+	size := 10000
+	if len(tokenCacheSize) != 0 {
+		_size, err := strconv.Atoi(tokenCacheSize)
+		if err != nil || _size < 0 {
+			return fmt.Errorf("invalid TOKEN_CACHE_SIZE: %s", tokenCacheSize)
+		}
+		size = _size
+	}
+	ttl := 5 * time.Minute
+	if len(tokenCacheTTL) != 0 {
+		_ttl, err := time.ParseDuration(tokenCacheTTL)
+		if err != nil || _ttl <= 0 {
+			return fmt.Errorf("invalid TOKEN_CACHE_TTL: %s", tokenCacheTTL)
+		}
+		ttl = _ttl
+	}
+	if size == 0 {
+		return nil
+	}
+
+	tokenCache = auth.NewTokenCache(size, ttl)
+	authenticator = auth.NewCachedAuthenticator(authenticator, tokenCache)
+	return nil
+}
+
+// Start purging the expired trash of all users every `TRASH_PURGE_INTERVAL`.
+// It must be called after InitState and InitStorage.
+func StartTrashPurge() error {
+	interval := time.Hour
+	if len(trashPurgeInterval) != 0 {
+		_interval, err := time.ParseDuration(trashPurgeInterval)
+		if err != nil || _interval < 0 {
+			return fmt.Errorf("invalid TRASH_PURGE_INTERVAL: %s", trashPurgeInterval)
+		}
+		interval = _interval
+	}
+	if interval == 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purgeAllTrash()
+		}
+	}()
+	return nil
+}
+
+// Purge the expired trash of all users.
+func purgeAllTrash() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		log.Printf("failed to purge the trash: %v", err)
+		return
+	}
+	if err := newSlideManager(ctx, "").PurgeAllTrash(storageOp); err != nil {
+		log.Printf("failed to purge the trash: %v", err)
+	}
+}
+
+// Create slide manager of the user on the selected state store.
+func newSlideManager(ctx context.Context, userId string) *slide.SlideManager {
+	if localState != nil {
+		return slide.NewSlideManagerWithState(ctx, localState, userId)
+	}
+	return slide.NewSlideManager(ctx, &client, userId)
+}
+
+// Create page data storage of the selected backend.
+func newBlobStore(ctx context.Context) (_storage.BlobStore, error) {
+	if storageBackend == "local" {
+		return _storage.NewLocalStorageOp(storageDir)
+	}
+	return _storage.NewStorageOp(ctx, *storageClient, pageBucketName), nil
+}
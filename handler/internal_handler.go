@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// User of the internal API.
+// The internal API is called by the other services via Dapr service invocation,
+// so the user is specified by the caller instead of the session.
+type InternalUser struct {
+	UserId string `json:"user_id"`
+}
+
+func (u *InternalUser) user() string {
+	return u.UserId
+}
+
+type InternalSlideRequest struct {
+	InternalUser
+	SlideRequest
+}
+
+type InternalPageRequest struct {
+	InternalUser
+	PageRequest
+}
+
+type InternalListRequest struct {
+	InternalUser
+	ListRequest
+}
+
+// Request of the internal API.
+type internalRequest interface {
+	v2Request
+	// Returns the user id specified by the caller.
+	user() string
+}
+
+// Create handler of the internal API.
+// It must be inside RequireApp, because it is authenticated by the app identity of Dapr instead of the session.
+//
+// Arguments:
+// - newRequest: returns the empty request.
+func internalHandler(newRequest func() internalRequest) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+
+		request := newRequest()
+		if err := decodeJSON(w, r, request); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_input", err)
+			return
+		}
+		if err := requireFields("user_id", request.user()); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_input", err)
+			return
+		}
+
+		slideManager := newSlideManager(ctx, request.user())
+		response, err := request.run(ctx, slideManager)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		writeResponse(w, r, http.StatusOK, response)
+	}
+}
+
+// Verify that the request of the internal API is sent by Dapr on behalf of an allowed app.
+func RequireApp(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if httpStatus, code, err := authenticateApp(r); err != nil {
+			writeError(w, httpStatus, code, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Verify that the request is sent by Dapr on behalf of an allowed app.
+// Dapr sets `dapr-api-token` to APP_API_TOKEN and `dapr-caller-app-id` to the caller.
+// The internal API is disabled if APP_API_TOKEN is not set, and every app is forbidden if INTERNAL_ALLOWED_APPS is not set.
+//
+// Return:
+// - int: http status if failed.
+// - string: error code if failed.
+// - error: nil if authenticated.
+func authenticateApp(r *http.Request) (int, string, error) {
+	if len(appAPIToken) == 0 {
+		return http.StatusServiceUnavailable, "internal_api_disabled", fmt.Errorf("the internal API is disabled")
+	}
+	token := r.Header.Get("dapr-api-token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(appAPIToken)) != 1 {
+		return http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid app api token")
+	}
+
+	// No app is allowed if INTERNAL_ALLOWED_APPS is not set.
+	callerAppId := r.Header.Get("dapr-caller-app-id")
+	for _, appId := range strings.Split(internalAllowedApps, ",") {
+		if len(callerAppId) != 0 && strings.TrimSpace(appId) == callerAppId {
+			return 0, "", nil
+		}
+	}
+	return http.StatusForbidden, "forbidden", fmt.Errorf("the app is not allowed to call the internal API")
+}
+
+// Internal API for the other services.
+var InternalDetailsHandler = internalHandler(func() internalRequest { return &InternalSlideRequest{} })
+var InternalGetPageHandler = internalHandler(func() internalRequest { return &InternalPageRequest{} })
+var InternalListHandler = internalHandler(func() internalRequest { return &InternalListRequest{} })
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticateApp(t *testing.T) {
+	defer func(token string, apps string) {
+		appAPIToken = token
+		internalAllowedApps = apps
+	}(appAPIToken, internalAllowedApps)
+
+	cases := []struct {
+		name        string
+		token       string
+		allowedApps string
+		headerToken string
+		callerAppId string
+		status      int
+	}{
+		{name: "disabled", allowedApps: "viewer", headerToken: "", callerAppId: "viewer", status: http.StatusServiceUnavailable},
+		{name: "invalid token", token: "secret", allowedApps: "viewer", headerToken: "wrong", callerAppId: "viewer", status: http.StatusUnauthorized},
+		{name: "no allowed apps", token: "secret", headerToken: "secret", callerAppId: "viewer", status: http.StatusForbidden},
+		{name: "not allowed", token: "secret", allowedApps: "viewer", headerToken: "secret", callerAppId: "other", status: http.StatusForbidden},
+		{name: "no caller", token: "secret", allowedApps: "viewer, ", headerToken: "secret", status: http.StatusForbidden},
+		{name: "allowed", token: "secret", allowedApps: "editor, viewer", headerToken: "secret", callerAppId: "viewer", status: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			appAPIToken = c.token
+			internalAllowedApps = c.allowedApps
+
+			r := httptest.NewRequest(http.MethodPost, "/internal/slide/details", nil)
+			r.Header.Set("dapr-api-token", c.headerToken)
+			r.Header.Set("dapr-caller-app-id", c.callerAppId)
+			status, _, err := authenticateApp(r)
+			if status != c.status {
+				t.Errorf("status: got %d, want %d (%v)", status, c.status, err)
+			}
+			if (err == nil) != (c.status == 0) {
+				t.Errorf("error: got %v", err)
+			}
+		})
+	}
+}
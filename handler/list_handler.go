@@ -4,33 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	_url "net/url"
 
 	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
 	"github.com/hello-slide/slide-manager/slide"
-	"github.com/hello-slide/slide-manager/utils"
 )
 
 func ListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/list")
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
-		return
-	}
-	if len(userId) == 0 {
-		return
-	}
+	userId := auth.UserId(r.Context())
 
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
-	slideConfig, err := slideManager.GetInfo()
+	slideManager := newSlideManager(ctx, userId)
+
+	// Without the query, returns all slides in the format before the listing options.
+	var slides interface{}
+	var err error
+	if len(r.URL.RawQuery) == 0 {
+		slides, err = slideManager.GetAllSlides()
+	} else {
+		slides, err = listSlides(slideManager, r.URL.Query())
+	}
 	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+		errorResponse(w, err)
 		return
 	}
 
-	tokenJson, err := json.Marshal(slideConfig)
+	tokenJson, err := json.Marshal(slides)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
@@ -39,3 +41,16 @@ func ListHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(tokenJson)
 }
+
+// List slides with the options in the url query.
+func listSlides(slideManager *slide.SlideManager, query _url.Values) (*slide.SlideList, error) {
+	request, err := listRequestFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	options, err := request.options()
+	if err != nil {
+		return nil, err
+	}
+	return slideManager.ListSlides(options)
+}
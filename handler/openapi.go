@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+// Document of an API.
+type apiDoc struct {
+	method  string
+	path    string
+	summary string
+	// Keys of the json object of the legacy API. All values are strings.
+	legacyKeys []string
+	// Zero value of the json request body.
+	request interface{}
+	// Url query parameters.
+	query []string
+	// Zero value of the json response, or oneOf of them. No content if nil.
+	response interface{}
+	// http status of the successful response. 200 if zero.
+	status int
+	// The request or response body is the raw page data.
+	rawRequest  bool
+	rawResponse bool
+	// Authenticated by the Dapr app api token instead of the session.
+	internal bool
+	// Does not require authentication.
+	public bool
+}
+
+// Response that is one of the types.
+type oneOf []interface{}
+
+// Documents of all APIs.
+var apiDocs = []apiDoc{
+	{method: http.MethodGet, path: "/", summary: "Health check.", rawResponse: true, public: true},
+	{method: http.MethodGet, path: "/openapi.json", summary: "This OpenAPI document.", response: map[string]interface{}{}, public: true},
+	{method: http.MethodGet, path: "/share/{token}", summary: "Open the slide by the share link. The password is sent in X-Share-Password header.",
+		response: ShareLinkResponse{}, public: true},
+	{method: http.MethodGet, path: "/share/{token}/pages/{page_id}", summary: "Download page data by the share link. The password is sent in X-Share-Password header.",
+		rawResponse: true, public: true},
+
+	{method: http.MethodPost, path: "/slide/create", summary: "Create slide.", legacyKeys: []string{"Title"}, response: CreateResponse{}},
+	{method: http.MethodPost, path: "/slide/createpage", summary: "Create page.", legacyKeys: []string{"SlideID", "PageType"}, response: slide.PageData{}},
+	{method: http.MethodPost, path: "/slide/list", summary: "List slides. Without the query, returns all slides with the shared ones as SlideConfig.",
+		query: []string{"cursor", "limit", "sort", "order", "created_after", "created_before", "changed_after", "changed_before"}, response: oneOf{slide.SlideConfig{}, slide.SlideList{}}},
+	{method: http.MethodPost, path: "/slide/search", summary: "Search slides and pages.", legacyKeys: []string{"Query"}, response: slide.SearchResult{}},
+	{method: http.MethodPost, path: "/slide/details", summary: "Get slide details. Supports If-None-Match and If-Modified-Since.", legacyKeys: []string{"SlideID"}, response: slide.SlideData{}},
+	{method: http.MethodPost, path: "/slide/rename", summary: "Rename slide.", legacyKeys: []string{"SlideID", "newName"}},
+	{method: http.MethodPost, path: "/slide/swap", summary: "Swap pages.", legacyKeys: []string{"SlideID", "Origin", "Target"}},
+	{method: http.MethodPost, path: "/slide/movepage", summary: "Move page to the index.", legacyKeys: []string{"SlideID", "PageID", "Index"}},
+	{method: http.MethodPost, path: "/slide/setorder", summary: "Set the order of all pages. Order is the comma-separated page ids.", legacyKeys: []string{"SlideID", "Order"}},
+	{method: http.MethodPost, path: "/slide/duplicate", summary: "Duplicate slide.", legacyKeys: []string{"SlideID", "Title"}, response: CreateResponse{}},
+	{method: http.MethodPost, path: "/slide/batch", summary: "Apply the operations to a slide in one write.", request: BatchRequest{}, response: BatchResponse{}},
+	{method: http.MethodPost, path: "/slide/setpage", summary: "Write page data. With application/octet-stream, the body is the page data and the ids are the SlideID and PageID query. Supports If-Match.",
+		legacyKeys: []string{"SlideID", "PageID", "Data"}},
+	{method: http.MethodPost, path: "/slide/getpage", summary: "Get page data. Supports If-None-Match and If-Modified-Since.", legacyKeys: []string{"SlideID", "PageID"}, rawResponse: true},
+	{method: http.MethodPost, path: "/slide/revisions", summary: "Get revisions of page.", legacyKeys: []string{"SlideID", "PageID"}, response: slide.PageRevisions{}},
+	{method: http.MethodPost, path: "/slide/getrevision", summary: "Get page data of the revision.", legacyKeys: []string{"SlideID", "PageID", "Revision"}, rawResponse: true},
+	{method: http.MethodPost, path: "/slide/restorerevision", summary: "Restore the revision as the current page.", legacyKeys: []string{"SlideID", "PageID", "Revision"}},
+	{method: http.MethodPost, path: "/slide/delete", summary: "Move slide to the trash.", legacyKeys: []string{"SlideID"}},
+	{method: http.MethodPost, path: "/slide/deleteall", summary: "Move all slides to the trash."},
+	{method: http.MethodPost, path: "/slide/deletepage", summary: "Move page to the trash.", legacyKeys: []string{"SlideID", "PageID"}},
+	{method: http.MethodPost, path: "/slide/trash", summary: "Get the trash.", response: slide.Trash{}},
+	{method: http.MethodPost, path: "/slide/restore", summary: "Restore the item in the trash.", legacyKeys: []string{"TrashID"}},
+	{method: http.MethodPost, path: "/slide/emptytrash", summary: "Empty the trash."},
+	{method: http.MethodPost, path: "/slide/usage", summary: "Get the usage against the quotas. The limit is 0 if it is unlimited.", response: slide.Usage{}},
+
+	{method: http.MethodPost, path: "/v2/slide/create", summary: "Create slide.", request: CreateRequest{}, response: CreateResponse{}},
+	{method: http.MethodPost, path: "/v2/slide/createpage", summary: "Create page.", request: CreatePageRequest{}, response: slide.PageData{}},
+	{method: http.MethodPost, path: "/v2/slide/list", summary: "List slides. The slides shared with the user have owner_id and role.", request: ListRequest{}, response: slide.SlideList{}},
+	{method: http.MethodPost, path: "/v2/slide/search", summary: "Search slides and pages.", request: SearchRequest{}, response: slide.SearchResult{}},
+	{method: http.MethodPost, path: "/v2/slide/details", summary: "Get slide details.", request: SlideRequest{}, response: slide.SlideData{}},
+	{method: http.MethodPost, path: "/v2/slide/rename", summary: "Rename slide.", request: RenameRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/swap", summary: "Swap pages.", request: SwapRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/movepage", summary: "Move page to the index.", request: MovePageRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/setorder", summary: "Set the order of all pages.", request: SetOrderRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/duplicate", summary: "Duplicate slide.", request: DuplicateRequest{}, response: CreateResponse{}},
+	{method: http.MethodPost, path: "/v2/slide/batch", summary: "Apply the operations to a slide in one write.", request: BatchRequest{}, response: BatchResponse{}},
+	{method: http.MethodPost, path: "/v2/slide/setpage", summary: "Write page data. Supports If-Match.", request: SetPageRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/getpage", summary: "Get page data.", request: PageRequest{}, response: PageResponse{}},
+	{method: http.MethodPost, path: "/v2/slide/revisions", summary: "Get revisions of page.", request: PageRequest{}, response: slide.PageRevisions{}},
+	{method: http.MethodPost, path: "/v2/slide/getrevision", summary: "Get page data of the revision.", request: RevisionRequest{}, response: RevisionResponse{}},
+	{method: http.MethodPost, path: "/v2/slide/restorerevision", summary: "Restore the revision as the current page.", request: RevisionRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/delete", summary: "Move slide to the trash.", request: SlideRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/deleteall", summary: "Move all slides to the trash.", request: EmptyRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/deletepage", summary: "Move page to the trash.", request: PageRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/trash", summary: "Get the trash.", request: EmptyRequest{}, response: slide.Trash{}},
+	{method: http.MethodPost, path: "/v2/slide/restore", summary: "Restore the item in the trash.", request: RestoreRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/emptytrash", summary: "Empty the trash.", request: EmptyRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/usage", summary: "Get the usage against the quotas. The limit is 0 if it is unlimited.", request: EmptyRequest{}, response: slide.Usage{}},
+	{method: http.MethodPost, path: "/v2/slide/share", summary: "Share slide with the user as viewer or editor. Only the owner can share it.", request: ShareRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/unshare", summary: "Stop sharing slide with the user.", request: UnshareRequest{}},
+	{method: http.MethodPost, path: "/v2/slide/grants", summary: "Get the users that the slide is shared with.", request: SlideRequest{}, response: slide.SlideACL{}},
+	{method: http.MethodPost, path: "/v2/slide/createlink", summary: "Create a public read-only share link. The token is returned only once.",
+		request: CreateLinkRequest{}, response: slide.CreatedShareLink{}},
+	{method: http.MethodPost, path: "/v2/slide/links", summary: "Get the active share links of the slide.", request: SlideRequest{}, response: slide.ShareLinks{}},
+	{method: http.MethodPost, path: "/v2/slide/revokelink", summary: "Revoke the share link.", request: RevokeLinkRequest{}},
+
+	{method: http.MethodGet, path: "/slides", summary: "List slides. The slides shared with the user have owner_id and role.",
+		query: []string{"cursor", "limit", "sort", "order", "created_after", "created_before", "changed_after", "changed_before"}, response: slide.SlideList{}},
+	{method: http.MethodPost, path: "/slides", summary: "Create slide.", request: CreateRequest{}, response: CreateResponse{}, status: http.StatusCreated},
+	{method: http.MethodDelete, path: "/slides", summary: "Move all slides to the trash."},
+	{method: http.MethodGet, path: "/slides:search", summary: "Search slides and pages.", query: []string{"q", "limit"}, response: slide.SearchResult{}},
+	{method: http.MethodGet, path: "/slides/{slide_id}", summary: "Get slide details. Supports If-None-Match and If-Modified-Since.", response: slide.SlideData{}},
+	{method: http.MethodPatch, path: "/slides/{slide_id}", summary: "Rename slide.", request: RenameRequest{}},
+	{method: http.MethodDelete, path: "/slides/{slide_id}", summary: "Move slide to the trash."},
+	{method: http.MethodPost, path: "/slides/{slide_id}:duplicate", summary: "Duplicate slide.", request: DuplicateRequest{}, response: CreateResponse{}, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/slides/{slide_id}:batch", summary: "Apply the operations to a slide in one write.", request: BatchRequest{}, response: BatchResponse{}},
+	{method: http.MethodGet, path: "/slides/{slide_id}/grants", summary: "Get the users that the slide is shared with.", response: slide.SlideACL{}},
+	{method: http.MethodPut, path: "/slides/{slide_id}/grants/{user_id}", summary: "Share slide with the user as viewer or editor. Only the owner can share it.", request: ShareRequest{}},
+	{method: http.MethodDelete, path: "/slides/{slide_id}/grants/{user_id}", summary: "Stop sharing slide with the user."},
+	{method: http.MethodGet, path: "/slides/{slide_id}/links", summary: "Get the active share links of the slide.", response: slide.ShareLinks{}},
+	{method: http.MethodPost, path: "/slides/{slide_id}/links", summary: "Create a public read-only share link. The token is returned only once.",
+		request: CreateLinkRequest{}, response: slide.CreatedShareLink{}, status: http.StatusCreated},
+	{method: http.MethodDelete, path: "/slides/{slide_id}/links/{link_id}", summary: "Revoke the share link."},
+	{method: http.MethodPost, path: "/slides/{slide_id}/pages", summary: "Create page.", request: CreatePageRequest{}, response: slide.PageData{}, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/slides/{slide_id}/pages:reorder", summary: "Set the order of all pages.", request: SetOrderRequest{}},
+	{method: http.MethodGet, path: "/slides/{slide_id}/pages/{page_id}", summary: "Get page data. Supports If-None-Match and If-Modified-Since.", response: PageResponse{}},
+	{method: http.MethodPut, path: "/slides/{slide_id}/pages/{page_id}", summary: "Write page data. Supports If-Match.", request: SetPageRequest{}},
+	{method: http.MethodDelete, path: "/slides/{slide_id}/pages/{page_id}", summary: "Move page to the trash."},
+	{method: http.MethodGet, path: "/slides/{slide_id}/pages/{page_id}/data", summary: "Download page data. Supports If-None-Match and If-Modified-Since.", rawResponse: true},
+	{method: http.MethodPut, path: "/slides/{slide_id}/pages/{page_id}/data", summary: "Upload page data. Supports If-Match.", rawRequest: true},
+	{method: http.MethodPost, path: "/slides/{slide_id}/pages/{page_id}:move", summary: "Move page to the index.", request: MovePageRequest{}},
+	{method: http.MethodGet, path: "/slides/{slide_id}/pages/{page_id}/revisions", summary: "Get revisions of page.", response: slide.PageRevisions{}},
+	{method: http.MethodGet, path: "/slides/{slide_id}/pages/{page_id}/revisions/{revision}", summary: "Get page data of the revision.", response: RevisionResponse{}},
+	{method: http.MethodPost, path: "/slides/{slide_id}/pages/{page_id}/revisions/{revision}:restore", summary: "Restore the revision as the current page."},
+	{method: http.MethodGet, path: "/trash", summary: "Get the trash.", response: slide.Trash{}},
+	{method: http.MethodDelete, path: "/trash", summary: "Empty the trash."},
+	{method: http.MethodPost, path: "/trash/{trash_id}:restore", summary: "Restore the item in the trash."},
+	{method: http.MethodGet, path: "/usage", summary: "Get the usage against the quotas. The limit is 0 if it is unlimited.", response: slide.Usage{}},
+
+	{method: http.MethodPost, path: "/internal/slide/details", summary: "Get slide details of the user.", request: InternalSlideRequest{}, response: slide.SlideData{}, internal: true},
+	{method: http.MethodPost, path: "/internal/slide/getpage", summary: "Get page data of the user.", request: InternalPageRequest{}, response: PageResponse{}, internal: true},
+	{method: http.MethodPost, path: "/internal/slide/list", summary: "List slides of the user.", request: InternalListRequest{}, response: slide.SlideList{}, internal: true},
+
+	{method: http.MethodGet, path: "/dapr/subscribe", summary: "Pubsub subscriptions of this app.", response: []DaprSubscription{}, internal: true},
+	{method: http.MethodPost, path: sessionRevokedPath, summary: "Remove the revoked session from the token cache.", request: SessionRevokedCloudEvent{}, response: DaprEventResponse{}, internal: true},
+}
+
+// OpenAPI document built from apiDocs.
+var openAPIDocument = buildOpenAPI()
+
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := json.Marshal(openAPIDocument)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// Returns an error if a registered path or a REST route is not in the OpenAPI document.
+// It is checked by the test so that the document does not miss any API.
+//
+// Arguments:
+// - paths: paths registered to the mux. A path ending with `/` is a subtree.
+func CheckOpenAPI(paths []string) error {
+	documented := map[string]bool{}
+	for _, doc := range apiDocs {
+		documented[doc.path] = true
+		documented[doc.method+" "+doc.path] = true
+	}
+
+	missing := []string{}
+	for _, path := range paths {
+		if path == "/" || !strings.HasSuffix(path, "/") {
+			if !documented[path] {
+				missing = append(missing, path)
+			}
+			continue
+		}
+
+		// The subtree is routed by the REST routes.
+		isDocumented := false
+		for _, doc := range apiDocs {
+			if strings.HasPrefix(doc.path, path) {
+				isDocumented = true
+				break
+			}
+		}
+		if !isDocumented {
+			missing = append(missing, path)
+		}
+	}
+	for _, route := range restRoutes {
+		for method := range route.methods {
+			if !documented[method+" "+route.pattern] {
+				missing = append(missing, method+" "+route.pattern)
+			}
+		}
+	}
+
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("the OpenAPI document does not have: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Path parameter such as `{slide_id}`.
+var pathParamPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Build the OpenAPI 3 document.
+func buildOpenAPI() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	schemas["ErrorBody"] = schemaOf(reflect.TypeOf(errorBody{}), schemas)
+
+	paths := map[string]interface{}{}
+	for _, doc := range apiDocs {
+		pathItem, ok := paths[doc.path].(map[string]interface{})
+		if !ok {
+			pathItem = map[string]interface{}{}
+			paths[doc.path] = pathItem
+		}
+		pathItem[strings.ToLower(doc.method)] = doc.operation(schemas)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Slide Manager API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "Error. `code` is such as `slide_not_found`, `invalid_input` or `conflict`.",
+					"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/ErrorBody"}),
+				},
+				"Unauthenticated": map[string]interface{}{
+					"description": "The session is invalid or expired. The browser requesting html is redirected to /account/update instead.",
+					"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/ErrorBody"}),
+				},
+				"TooManyRequests": map[string]interface{}{
+					"description": "The user has sent too many requests to the path. `code` is `rate_limited`.",
+					"headers": map[string]interface{}{
+						"Retry-After": map[string]interface{}{
+							"description": "Seconds to wait before retrying.",
+							"schema":      map[string]interface{}{"type": "integer"},
+						},
+					},
+					"content": jsonContent(map[string]interface{}{"$ref": "#/components/schemas/ErrorBody"}),
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"session": map[string]interface{}{
+					"type": "apiKey",
+					"in":   "cookie",
+					"name": "session_token",
+				},
+				"bearer": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Session token for the API clients without the cookie.",
+				},
+				"dapr": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "dapr-api-token",
+					"description": "APP_API_TOKEN sent by Dapr service invocation. The caller must be in INTERNAL_ALLOWED_APPS.",
+				},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"session": []string{}},
+			map[string]interface{}{"bearer": []string{}},
+		},
+	}
+}
+
+// Returns the operation object of the API.
+func (doc *apiDoc) operation(schemas map[string]interface{}) map[string]interface{} {
+	parameters := []interface{}{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(doc.path, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, name := range doc.query {
+		parameters = append(parameters, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+
+	status := doc.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	successResponse := map[string]interface{}{
+		"description": http.StatusText(status),
+	}
+	switch {
+	case doc.rawResponse:
+		successResponse["content"] = map[string]interface{}{
+			"text/plain": map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string"},
+			},
+		}
+	case doc.response != nil:
+		if responses, ok := doc.response.(oneOf); ok {
+			oneOfSchemas := []interface{}{}
+			for _, response := range responses {
+				oneOfSchemas = append(oneOfSchemas, schemaOf(reflect.TypeOf(response), schemas))
+			}
+			successResponse["content"] = jsonContent(map[string]interface{}{"oneOf": oneOfSchemas})
+		} else {
+			successResponse["content"] = jsonContent(schemaOf(reflect.TypeOf(doc.response), schemas))
+		}
+	default:
+		status = http.StatusNoContent
+		successResponse["description"] = http.StatusText(status)
+	}
+
+	responses := map[string]interface{}{
+		fmt.Sprint(status): successResponse,
+		"default":          map[string]interface{}{"$ref": "#/components/responses/Error"},
+	}
+	operation := map[string]interface{}{
+		"summary":    doc.summary,
+		"parameters": parameters,
+		"responses":  responses,
+	}
+	if doc.public {
+		operation["security"] = []interface{}{}
+	} else if !doc.internal {
+		responses["401"] = map[string]interface{}{"$ref": "#/components/responses/Unauthenticated"}
+		responses["429"] = map[string]interface{}{"$ref": "#/components/responses/TooManyRequests"}
+	}
+	if doc.internal {
+		operation["security"] = []interface{}{
+			map[string]interface{}{"dapr": []string{}},
+		}
+	}
+
+	switch {
+	case doc.rawRequest:
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/octet-stream": map[string]interface{}{
+					"schema": map[string]interface{}{"type": "string", "format": "binary"},
+				},
+			},
+		}
+	case len(doc.legacyKeys) != 0:
+		properties := map[string]interface{}{}
+		for _, key := range doc.legacyKeys {
+			properties[key] = map[string]interface{}{"type": "string"}
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": jsonContent(map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   doc.legacyKeys,
+			}),
+		}
+	case doc.request != nil:
+		operation["requestBody"] = map[string]interface{}{
+			"content": jsonContent(schemaOf(reflect.TypeOf(doc.request), schemas)),
+		}
+	}
+	return operation
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": schema,
+		},
+	}
+}
+
+// Returns the json schema of the type.
+// The named structs are added to schemas and referred by `$ref`.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// Register first for the recursive types.
+			schemas[t.Name()] = map[string]interface{}{}
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// Returns the json schema of the struct by its json tags.
+// The fields of the embedded struct are flattened as encoding/json does.
+// The fields are not marked as required because the empty request fields are allowed.
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for index := 0; index < t.NumField(); index++ {
+			field := t.Field(index)
+			tag := field.Tag.Get("json")
+			if field.Anonymous && len(tag) == 0 {
+				addFields(field.Type)
+				continue
+			}
+			if len(field.PkgPath) != 0 || tag == "-" {
+				continue
+			}
+
+			name := field.Name
+			if options := strings.Split(tag, ","); len(options[0]) != 0 {
+				name = options[0]
+			}
+			properties[name] = schemaOf(field.Type, schemas)
+		}
+	}
+	addFields(t)
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	paths := []string{}
+	for _, route := range Routes() {
+		paths = append(paths, route.Path)
+	}
+	if err := CheckOpenAPI(paths); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckOpenAPIReportsMissingPaths(t *testing.T) {
+	err := CheckOpenAPI([]string{"/slide/create", "/slide/unknown", "/unknown/"})
+	if err == nil {
+		t.Fatal("no error for the undocumented paths")
+	}
+	for _, path := range []string{"/slide/unknown", "/unknown/"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("%s is not reported: %v", path, err)
+		}
+	}
+	if strings.Contains(err.Error(), "/slide/create,") {
+		t.Errorf("the documented path is reported: %v", err)
+	}
+}
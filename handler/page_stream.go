@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/hello-slide/slide-manager/slide"
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+// Response that streams the page data instead of json.
+type pageStream struct {
+	reader io.ReadCloser
+	info   *slide.PageInfo
+}
+
+// Request that reads the http request in addition to the parsed values,
+// such as the request body and the headers.
+type rawRequest interface {
+	setRequest(r *http.Request)
+}
+
+// Download the page data.
+type downloadPageRequest PageRequest
+
+// Upload the page data in the request body.
+type uploadPageRequest struct {
+	PageRequest
+	body io.Reader
+	// Content-Length. -1 if unknown.
+	size    int64
+	ifMatch string
+}
+
+func (req *uploadPageRequest) setRequest(r *http.Request) {
+	req.body = r.Body
+	req.size = r.ContentLength
+	req.ifMatch = ifMatchETag(r)
+}
+
+func (req *downloadPageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader, pageInfo, err := slideManager.OpenPage(req.SlideId, req.PageId, storageOp)
+	if err != nil {
+		return nil, err
+	}
+	return &pageStream{reader: reader, info: pageInfo}, nil
+}
+
+func (req *uploadPageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := slideManager.SetPageFrom(req.body, req.size, req.SlideId, req.PageId, req.ifMatch, storageOp); err != nil {
+		return nil, err
+	}
+	return pageWritten(slideManager, req.SlideId, req.PageId, storageOp), nil
+}
+
+// Returns the empty response with the ETag of the written page.
+// The ETag is not sent if it can not be read because the page has been written successfully.
+func pageWritten(slideManager *slide.SlideManager, slideId string, pageId string, storageOp storage.BlobStore) interface{} {
+	pageInfo, err := slideManager.StatPage(slideId, pageId, storageOp)
+	if err != nil {
+		return nil
+	}
+	return &conditionalResponse{
+		etag:         pageInfo.ETag,
+		lastModified: pageInfo.ModTime,
+	}
+}
+
+// Write the page data with Content-Length.
+// The error after writing the header can not be sent, so it is only logged.
+func writeStream(w http.ResponseWriter, httpStatus int, stream *pageStream) {
+	defer stream.reader.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Header().Set("Content-Length", strconv.FormatInt(stream.info.Size, 10))
+	w.WriteHeader(httpStatus)
+
+	if _, err := io.Copy(w, stream.reader); err != nil {
+		log.Printf("failed to write page data: %v", err)
+	}
+}
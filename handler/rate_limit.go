@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hello-slide/slide-manager/auth"
+	"github.com/hello-slide/slide-manager/ratelimit"
+)
+
+// Limit of the routes without RATE_LIMIT_ROUTES.
+var defaultRateLimit = ratelimit.Limit{Rate: 10, Burst: 20}
+
+// Limits by the route configured by RATE_LIMIT_ROUTES.
+var routeRateLimits = map[string]ratelimit.Limit{}
+
+// Networks of the proxies configured by TRUSTED_PROXIES.
+var trustedProxyNets = []*net.IPNet{}
+
+// Parse RATE_LIMIT_RATE, RATE_LIMIT_BURST, RATE_LIMIT_ROUTES and TRUSTED_PROXIES.
+func parseRateLimits() error {
+	if len(rateLimitRate) != 0 {
+		rate, err := strconv.ParseFloat(rateLimitRate, 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("invalid RATE_LIMIT_RATE: %s", rateLimitRate)
+		}
+		defaultRateLimit.Rate = rate
+	}
+	if len(rateLimitBurst) != 0 {
+		burst, err := strconv.Atoi(rateLimitBurst)
+		if err != nil || burst < 1 {
+			return fmt.Errorf("invalid RATE_LIMIT_BURST: %s", rateLimitBurst)
+		}
+		defaultRateLimit.Burst = burst
+	}
+
+	for _, routeLimit := range strings.Split(rateLimitRoutes, ",") {
+		routeLimit = strings.TrimSpace(routeLimit)
+		if len(routeLimit) == 0 {
+			continue
+		}
+		route, value, ok := cut(routeLimit, "=")
+		if !ok {
+			return fmt.Errorf("invalid RATE_LIMIT_ROUTES: %s", routeLimit)
+		}
+		rateValue, burstValue, ok := cut(value, ":")
+		if !ok {
+			return fmt.Errorf("invalid RATE_LIMIT_ROUTES: %s", routeLimit)
+		}
+		rate, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("invalid RATE_LIMIT_ROUTES: %s", routeLimit)
+		}
+		burst, err := strconv.Atoi(burstValue)
+		if err != nil || burst < 1 {
+			return fmt.Errorf("invalid RATE_LIMIT_ROUTES: %s", routeLimit)
+		}
+		routeRateLimits[route] = ratelimit.Limit{Rate: rate, Burst: burst}
+	}
+
+	for _, proxy := range strings.Split(trustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if len(proxy) == 0 {
+			continue
+		}
+		// An address is the network of only itself.
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trustedProxyNets = append(trustedProxyNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES: %s", proxy)
+		}
+		trustedProxyNets = append(trustedProxyNets, network)
+	}
+	return nil
+}
+
+// Split s around the first sep.
+func cut(s string, sep string) (string, string, bool) {
+	index := strings.Index(s, sep)
+	if index < 0 {
+		return s, "", false
+	}
+	return s[:index], s[index+len(sep):], true
+}
+
+// Limit the requests of the user to the route by the token bucket.
+// It must be inside RequireUser because the bucket is of the user.
+//
+// Arguments:
+// - route: registered path of the handler. The limit is of each route.
+// - next: handler.
+func RateLimit(route string, next http.HandlerFunc) http.HandlerFunc {
+	return rateLimit(routeOperation(route), userKeys, next)
+}
+
+// Limit the requests of the user to the REST API by the token bucket of each operation, such as `PUT /slides/{slide_id}/pages/{page_id}`.
+// The limit of the operation in RATE_LIMIT_ROUTES is used, or that of the route if the operation is not configured.
+// It must be inside RequireUser because the bucket is of the user.
+//
+// Arguments:
+// - route: registered path of the handler. It is the operation of the request not matching any REST route.
+// - next: handler.
+func RateLimitRest(route string, next http.HandlerFunc) http.HandlerFunc {
+	return rateLimit(func(r *http.Request) []string {
+		if operation, ok := restOperation(r); ok {
+			return []string{operation, route}
+		}
+		return []string{route}
+	}, userKeys, next)
+}
+
+// Limit the requests to the share links by the client, and the password attempts by the link.
+//
+// Arguments:
+// - route: registered path of the handler. The limit is of each route.
+// - next: handler.
+func RateLimitShareLink(route string, next http.HandlerFunc) http.HandlerFunc {
+	return rateLimit(routeOperation(route), shareLinkKeys, next)
+}
+
+// Limit the requests of the app to the internal API by the token bucket.
+// It must be inside RequireApp because the caller app id is trusted only after the authentication.
+//
+// Arguments:
+// - route: registered path of the handler. The limit is of each route.
+// - next: handler.
+func RateLimitApp(route string, next http.HandlerFunc) http.HandlerFunc {
+	return rateLimit(routeOperation(route), func(r *http.Request) []string {
+		return []string{strings.Join([]string{"app", r.Header.Get("dapr-caller-app-id")}, ":")}
+	}, next)
+}
+
+// Returns the operation of the fixed route.
+func routeOperation(route string) func(r *http.Request) []string {
+	return func(r *http.Request) []string {
+		return []string{route}
+	}
+}
+
+// Returns the rate limit key of the user.
+func userKeys(r *http.Request) []string {
+	return []string{auth.UserId(r.Context())}
+}
+
+// Limit the requests to the operation by the token bucket of each key.
+// The request is allowed only if all buckets have a token.
+// The request is not limited if the limiter fails, so that the API does not stop with the state store.
+//
+// Arguments:
+// - operations: returns the operation of the request that the bucket is of, and the fallbacks to find its limit.
+// - keys: returns the keys of the buckets for the request.
+// - next: handler.
+func rateLimit(operations func(r *http.Request) []string, keys func(r *http.Request) []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if rateLimiter == nil {
+			next(w, r)
+			return
+		}
+		names := operations(r)
+		limit := rateLimitOf(names)
+		if limit.Rate == 0 {
+			next(w, r)
+			return
+		}
+
+		for _, key := range keys(r) {
+			allowed, retryAfter, err := rateLimiter.Allow(strings.Join([]string{key, names[0]}, "|"), limit)
+			if err != nil {
+				log.Printf("failed to limit the request rate: %v", err)
+				continue
+			}
+			if !allowed {
+				seconds := int64(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+				writeError(w, http.StatusTooManyRequests, "rate_limited", fmt.Errorf("too many requests, retry after %s", time.Duration(seconds)*time.Second))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// Returns the limit of the first configured name, or the default limit.
+func rateLimitOf(names []string) ratelimit.Limit {
+	for _, name := range names {
+		if limit, ok := routeRateLimits[name]; ok {
+			return limit
+		}
+	}
+	return defaultRateLimit
+}
+
+// Returns the rate limit key of the client by its address.
+func clientKey(r *http.Request) string {
+	return strings.Join([]string{"ip", clientIP(r)}, ":")
+}
+
+// Returns the address of the client.
+// If the peer is a trusted proxy, the client is the nearest address in X-Forwarded-For that is not a trusted proxy.
+// The addresses before it are not used because the client can forge them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	forwarded := []string{}
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+	for index := len(forwarded) - 1; index >= 0; index-- {
+		address := strings.TrimSpace(forwarded[index])
+		// The broken address is not of a trusted proxy, so the last proxy is used.
+		if net.ParseIP(address) == nil {
+			break
+		}
+		host = address
+		if !isTrustedProxy(address) {
+			break
+		}
+	}
+	return host
+}
+
+// Returns true if the address is in TRUSTED_PROXIES.
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxyNets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
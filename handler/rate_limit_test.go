@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hello-slide/slide-manager/auth"
+	"github.com/hello-slide/slide-manager/ratelimit"
+)
+
+// Replace the rate limiter and the limits during the test.
+func setTestRateLimits(t *testing.T, limits map[string]ratelimit.Limit) {
+	t.Helper()
+	limiter, routeLimits, defaultLimit := rateLimiter, routeRateLimits, defaultRateLimit
+	t.Cleanup(func() {
+		rateLimiter, routeRateLimits, defaultRateLimit = limiter, routeLimits, defaultLimit
+	})
+	rateLimiter = ratelimit.NewMemoryLimiter(100)
+	routeRateLimits = limits
+	defaultRateLimit = ratelimit.Limit{Rate: 0.001, Burst: 1}
+}
+
+func TestRateLimitRestByOperation(t *testing.T) {
+	setTestRateLimits(t, map[string]ratelimit.Limit{
+		"PUT /slides/{slide_id}/pages/{page_id}/data": {Rate: 0.001, Burst: 2},
+	})
+	handler := RateLimitRest("/slides/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "configured operation", method: http.MethodPut, path: "/slides/a/pages/1/data", status: http.StatusOK},
+		{name: "same operation of another page", method: http.MethodPut, path: "/slides/b/pages/2/data", status: http.StatusOK},
+		{name: "configured operation is limited", method: http.MethodPut, path: "/slides/a/pages/1/data", status: http.StatusTooManyRequests},
+		{name: "another operation", method: http.MethodGet, path: "/slides/a/pages/1/data", status: http.StatusOK},
+		{name: "another operation is limited by default", method: http.MethodGet, path: "/slides/a/pages/1/data", status: http.StatusTooManyRequests},
+		{name: "another route", method: http.MethodGet, path: "/slides/a", status: http.StatusOK},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.path, nil)
+		r = r.WithContext(auth.WithUserId(r.Context(), "user"))
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != c.status {
+			t.Errorf("%s: got %d, want %d", c.name, w.Code, c.status)
+		}
+	}
+}
+
+func TestRateLimitApp(t *testing.T) {
+	setTestRateLimits(t, map[string]ratelimit.Limit{})
+	defer func(token string, apps string) {
+		appAPIToken = token
+		internalAllowedApps = apps
+	}(appAPIToken, internalAllowedApps)
+	appAPIToken = "secret"
+	internalAllowedApps = "viewer,editor"
+
+	handler := RequireApp(RateLimitApp("/internal/slide/details", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		name   string
+		token  string
+		appId  string
+		status int
+	}{
+		{name: "first", token: "secret", appId: "viewer", status: http.StatusOK},
+		{name: "unauthenticated is not counted", token: "wrong", appId: "editor", status: http.StatusUnauthorized},
+		{name: "another app", token: "secret", appId: "editor", status: http.StatusOK},
+		{name: "limited", token: "secret", appId: "viewer", status: http.StatusTooManyRequests},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/internal/slide/details", nil)
+		r.Header.Set("dapr-api-token", c.token)
+		r.Header.Set("dapr-caller-app-id", c.appId)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != c.status {
+			t.Errorf("%s: got %d, want %d", c.name, w.Code, c.status)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	defer func(proxies string, networks []*net.IPNet) {
+		trustedProxies = proxies
+		trustedProxyNets = networks
+	}(trustedProxies, trustedProxyNets)
+	trustedProxies = "10.0.0.0/8, 192.0.2.1"
+	trustedProxyNets = []*net.IPNet{}
+	if err := parseRateLimits(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		ip         string
+	}{
+		{name: "direct", remoteAddr: "198.51.100.1:1000", ip: "198.51.100.1"},
+		{name: "untrusted peer", remoteAddr: "198.51.100.1:1000", forwarded: []string{"203.0.113.1"}, ip: "198.51.100.1"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.1:1000", forwarded: []string{"203.0.113.1"}, ip: "203.0.113.1"},
+		{name: "forged addresses", remoteAddr: "10.0.0.1:1000", forwarded: []string{"203.0.113.9, 203.0.113.1"}, ip: "203.0.113.1"},
+		{name: "proxies", remoteAddr: "10.0.0.1:1000", forwarded: []string{"203.0.113.1, 192.0.2.1", "10.0.0.2"}, ip: "203.0.113.1"},
+		{name: "broken address", remoteAddr: "10.0.0.1:1000", forwarded: []string{"unknown, 10.0.0.2"}, ip: "10.0.0.2"},
+		{name: "no header", remoteAddr: "10.0.0.1:1000", ip: "10.0.0.1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/share/a", nil)
+		r.RemoteAddr = c.remoteAddr
+		for _, forwarded := range c.forwarded {
+			r.Header.Add("X-Forwarded-For", forwarded)
+		}
+		if ip := clientIP(r); ip != c.ip {
+			t.Errorf("%s: got %s, want %s", c.name, ip, c.ip)
+		}
+	}
+}
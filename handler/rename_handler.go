@@ -5,8 +5,7 @@ import (
 	"net/http"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
 func RenameHandler(w http.ResponseWriter, r *http.Request) {
@@ -29,18 +28,11 @@ func RenameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/rename")
-	if err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
-		return
-	}
-	if len(userId) == 0 {
-		return
-	}
+	userId := auth.UserId(r.Context())
 
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
+	slideManager := newSlideManager(ctx, userId)
 	if err := slideManager.Rename(slideId, newName); err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+		errorResponse(w, err)
 		return
 	}
 }
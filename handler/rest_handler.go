@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	_url "net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+// Path parameters of the REST API.
+type pathParams map[string]string
+
+// Returns the request of the REST API.
+// query is the url query, and decode decodes the json request body to the argument.
+type restParser func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error)
+
+type restMethod struct {
+	// http status of the successful response. 200 if zero.
+	status int
+	parse  restParser
+}
+
+type restRoute struct {
+	// Path pattern such as `/slides/{slide_id}/pages:reorder`.
+	// `{name}` is a path parameter.
+	pattern string
+	methods map[string]restMethod
+}
+
+// Routes of the REST API.
+var restRoutes = []restRoute{
+	{
+		pattern: "/slides",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return listRequestFromQuery(query)
+			}},
+			http.MethodPost: {status: http.StatusCreated, parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &CreateRequest{}
+				return request, decode(request)
+			}},
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &deleteAllRequest{}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides:search",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &SearchRequest{Query: query.Get("q")}
+				if limit := query.Get("limit"); len(limit) != 0 {
+					limitInt, err := strconv.Atoi(limit)
+					if err != nil {
+						return nil, slide.InvalidInput("limit must be a number")
+					}
+					request.Limit = limitInt
+				}
+				return request, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &SlideRequest{SlideId: params["slide_id"]}, nil
+			}},
+			http.MethodPatch: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &RenameRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &deleteSlideRequest{SlideId: params["slide_id"]}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}:duplicate",
+		methods: map[string]restMethod{
+			http.MethodPost: {status: http.StatusCreated, parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &DuplicateRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}:batch",
+		methods: map[string]restMethod{
+			http.MethodPost: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &BatchRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/grants",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &grantsRequest{SlideId: params["slide_id"]}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/grants/{user_id}",
+		methods: map[string]restMethod{
+			http.MethodPut: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &ShareRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				request.UserId = params["user_id"]
+				return request, err
+			}},
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &UnshareRequest{SlideId: params["slide_id"], UserId: params["user_id"]}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/links",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &linksRequest{SlideId: params["slide_id"]}, nil
+			}},
+			http.MethodPost: {status: http.StatusCreated, parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &CreateLinkRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/links/{link_id}",
+		methods: map[string]restMethod{
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &RevokeLinkRequest{SlideId: params["slide_id"], LinkId: params["link_id"]}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages",
+		methods: map[string]restMethod{
+			http.MethodPost: {status: http.StatusCreated, parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &CreatePageRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages:reorder",
+		methods: map[string]restMethod{
+			http.MethodPost: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &SetOrderRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages/{page_id}",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &PageRequest{SlideId: params["slide_id"], PageId: params["page_id"]}, nil
+			}},
+			http.MethodPut: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &SetPageRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				request.PageId = params["page_id"]
+				return request, err
+			}},
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &deletePageRequest{SlideId: params["slide_id"], PageId: params["page_id"]}, nil
+			}},
+		},
+	},
+	{
+		// Raw page data streamed in the request and response bodies.
+		pattern: "/slides/{slide_id}/pages/{page_id}/data",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &downloadPageRequest{SlideId: params["slide_id"], PageId: params["page_id"]}, nil
+			}},
+			http.MethodPut: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &uploadPageRequest{PageRequest: PageRequest{SlideId: params["slide_id"], PageId: params["page_id"]}}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages/{page_id}:move",
+		methods: map[string]restMethod{
+			http.MethodPost: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				request := &MovePageRequest{}
+				err := decode(request)
+				request.SlideId = params["slide_id"]
+				request.PageId = params["page_id"]
+				return request, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages/{page_id}/revisions",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &revisionsRequest{SlideId: params["slide_id"], PageId: params["page_id"]}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages/{page_id}/revisions/{revision}",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				revision, err := parseRevision(params)
+				return &RevisionRequest{SlideId: params["slide_id"], PageId: params["page_id"], Revision: revision}, err
+			}},
+		},
+	},
+	{
+		pattern: "/slides/{slide_id}/pages/{page_id}/revisions/{revision}:restore",
+		methods: map[string]restMethod{
+			http.MethodPost: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				revision, err := parseRevision(params)
+				return &restoreRevisionRequest{SlideId: params["slide_id"], PageId: params["page_id"], Revision: revision}, err
+			}},
+		},
+	},
+	{
+		pattern: "/trash",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &trashRequest{}, nil
+			}},
+			http.MethodDelete: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &emptyTrashRequest{}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/usage",
+		methods: map[string]restMethod{
+			http.MethodGet: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &usageRequest{}, nil
+			}},
+		},
+	},
+	{
+		pattern: "/trash/{trash_id}:restore",
+		methods: map[string]restMethod{
+			http.MethodPost: {parse: func(params pathParams, query _url.Values, decode func(v interface{}) error) (v2Request, error) {
+				return &RestoreRequest{TrashId: params["trash_id"]}, nil
+			}},
+		},
+	},
+}
+
+// Handler of the REST API.
+// It dispatches the request by the path and the http method.
+func RestHandler(w http.ResponseWriter, r *http.Request) {
+	route, params := matchRoute(r.URL.Path)
+	if route == nil {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Errorf("not found"))
+		return
+	}
+
+	method, ok := route.methods[r.Method]
+	if !ok {
+		methods := []string{}
+		for name := range route.methods {
+			methods = append(methods, name)
+		}
+		sort.Strings(methods)
+		methodNotAllowed(w, methods)
+		return
+	}
+
+	httpStatus := method.status
+	if httpStatus == 0 {
+		httpStatus = http.StatusOK
+	}
+	serveRequest(w, r, httpStatus, func() (v2Request, error) {
+		return method.parse(params, r.URL.Query(), func(v interface{}) error {
+			return decodeJSON(w, r, v)
+		})
+	})
+}
+
+// Returns the REST operation of the request, such as `GET /slides/{slide_id}`.
+// Returns false if the request does not match any route and method.
+func restOperation(r *http.Request) (string, bool) {
+	route, _ := matchRoute(r.URL.Path)
+	if route == nil {
+		return "", false
+	}
+	if _, ok := route.methods[r.Method]; !ok {
+		return "", false
+	}
+	return strings.Join([]string{r.Method, route.pattern}, " "), true
+}
+
+// Parse the revision number in the path.
+func parseRevision(params pathParams) (int, error) {
+	revision, err := strconv.Atoi(params["revision"])
+	if err != nil {
+		return 0, slide.InvalidInput("revision must be a number")
+	}
+	return revision, nil
+}
+
+// Returns the route matching path and its path parameters.
+// The routes with a custom method are matched first, so that `/slides/{id}:duplicate` is not `/slides/{id}`.
+// If no route matches, returns nil.
+func matchRoute(path string) (*restRoute, pathParams) {
+	for _, withVerb := range []bool{true, false} {
+		for index := range restRoutes {
+			if hasVerb(restRoutes[index].pattern) != withVerb {
+				continue
+			}
+			if params, ok := matchPattern(restRoutes[index].pattern, path); ok {
+				return &restRoutes[index], params
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Returns true if the last segment of the pattern has a custom method such as `:reorder`.
+func hasVerb(pattern string) bool {
+	return strings.Contains(pattern[strings.LastIndex(pattern, "/")+1:], ":")
+}
+
+// Match path with the pattern.
+// A custom method such as `:reorder` is split from the path segment only if the pattern declares it,
+// so a path parameter may contain `:`.
+func matchPattern(pattern string, path string) (pathParams, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := pathParams{}
+	for index, patternSegment := range patternSegments {
+		pathSegment := pathSegments[index]
+
+		// Split the custom method.
+		if verbIndex := strings.LastIndex(patternSegment, ":"); verbIndex >= 0 {
+			patternVerb := patternSegment[verbIndex:]
+			if !strings.HasSuffix(pathSegment, patternVerb) {
+				return nil, false
+			}
+			patternSegment = patternSegment[:verbIndex]
+			pathSegment = strings.TrimSuffix(pathSegment, patternVerb)
+		}
+
+		if strings.HasPrefix(patternSegment, "{") && strings.HasSuffix(patternSegment, "}") {
+			if len(pathSegment) == 0 {
+				return nil, false
+			}
+			params[patternSegment[1:len(patternSegment)-1]] = pathSegment
+			continue
+		}
+		if patternSegment != pathSegment {
+			return nil, false
+		}
+	}
+	return params, true
+}
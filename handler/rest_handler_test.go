@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchRoute(t *testing.T) {
+	cases := []struct {
+		path    string
+		pattern string
+		params  pathParams
+	}{
+		{path: "/slides", pattern: "/slides", params: pathParams{}},
+		{path: "/slides:search", pattern: "/slides:search", params: pathParams{}},
+		{path: "/slides/s1", pattern: "/slides/{slide_id}", params: pathParams{"slide_id": "s1"}},
+		{path: "/slides/s1:duplicate", pattern: "/slides/{slide_id}:duplicate", params: pathParams{"slide_id": "s1"}},
+		{path: "/slides/s1/grants/u1", pattern: "/slides/{slide_id}/grants/{user_id}", params: pathParams{"slide_id": "s1", "user_id": "u1"}},
+		{path: "/slides/s1/grants/user:with:colon", pattern: "/slides/{slide_id}/grants/{user_id}", params: pathParams{"slide_id": "s1", "user_id": "user:with:colon"}},
+		{path: "/slides/s1/pages:reorder", pattern: "/slides/{slide_id}/pages:reorder", params: pathParams{"slide_id": "s1"}},
+		{path: "/slides/s1/pages/p1:move", pattern: "/slides/{slide_id}/pages/{page_id}:move", params: pathParams{"slide_id": "s1", "page_id": "p1"}},
+		{path: "/slides/s1/pages/p1/revisions/2:restore", pattern: "/slides/{slide_id}/pages/{page_id}/revisions/{revision}:restore", params: pathParams{"slide_id": "s1", "page_id": "p1", "revision": "2"}},
+		{path: "/trash/t1:restore", pattern: "/trash/{trash_id}:restore", params: pathParams{"trash_id": "t1"}},
+		{path: "/unknown", pattern: "", params: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			route, params := matchRoute(c.path)
+			pattern := ""
+			if route != nil {
+				pattern = route.pattern
+			}
+			if pattern != c.pattern {
+				t.Fatalf("pattern: got %q, want %q", pattern, c.pattern)
+			}
+			if !reflect.DeepEqual(params, c.params) {
+				t.Errorf("params: got %v, want %v", params, c.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+func RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headerData, err := networkUtils.GetHeader(w, r)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	trashId, err := networkUtils.PickValue("TrashID", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	if err := slideManager.RestoreTrash(trashId); err != nil {
+		errorResponse(w, err)
+		return
+	}
+}
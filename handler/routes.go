@@ -0,0 +1,116 @@
+package handler
+
+import "net/http"
+
+// Handler of the path registered to the mux.
+type Route struct {
+	// Path of the mux. A path ending with `/` is a subtree.
+	Path    string
+	Handler http.HandlerFunc
+}
+
+// Returns all routes of the service.
+// It is shared by main and the test checking that every API is in the OpenAPI document.
+func Routes() []Route {
+	routes := []Route{}
+	handle := func(path string, handlerFunc http.HandlerFunc) {
+		routes = append(routes, Route{Path: path, Handler: handlerFunc})
+	}
+	// APIs of the user session. The requests are limited for each user and path.
+	handleUser := func(path string, handlerFunc http.HandlerFunc) {
+		handle(path, RequireUser(RateLimit(path, handlerFunc)))
+	}
+	// REST APIs of the user session. The requests are limited for each user and operation.
+	handleRest := func(path string) {
+		handle(path, RequireUser(RateLimitRest(path, RestHandler)))
+	}
+	// Internal APIs. The requests are limited for each app and path.
+	handleApp := func(path string, handlerFunc http.HandlerFunc) {
+		handle(path, RequireApp(RateLimitApp(path, handlerFunc)))
+	}
+
+	handle("/", RootHandler)
+	handle("/openapi.json", OpenAPIHandler)
+	handle("/share/", RateLimitShareLink("/share/", ShareLinkHandler))
+
+	handleUser("/slide/create", CreateHandler)
+	handleUser("/slide/createpage", CreatePageHandler)
+
+	handleUser("/slide/list", ListHandler)
+	handleUser("/slide/search", SearchHandler)
+	handleUser("/slide/details", DetailsHandler)
+	handleUser("/slide/rename", RenameHandler)
+	handleUser("/slide/swap", SwapHandler)
+	handleUser("/slide/movepage", MovePageHandler)
+	handleUser("/slide/setorder", SetOrderHandler)
+	handleUser("/slide/duplicate", DuplicateHandler)
+	handleUser("/slide/batch", BatchHandler)
+
+	handleUser("/slide/setpage", SetPageHandler)
+	handleUser("/slide/getpage", GetPageHandler)
+
+	handleUser("/slide/revisions", RevisionsHandler)
+	handleUser("/slide/getrevision", GetRevisionHandler)
+	handleUser("/slide/restorerevision", RestoreRevisionHandler)
+
+	handleUser("/slide/delete", DeleteSlideHandler)
+	handleUser("/slide/deleteall", DeleteAllHandler)
+	handleUser("/slide/deletepage", DeletePageHandler)
+
+	handleUser("/slide/trash", TrashHandler)
+	handleUser("/slide/restore", RestoreHandler)
+	handleUser("/slide/emptytrash", EmptyTrashHandler)
+	handleUser("/slide/usage", UsageHandler)
+
+	handleUser("/v2/slide/create", V2CreateHandler)
+	handleUser("/v2/slide/createpage", V2CreatePageHandler)
+
+	handleUser("/v2/slide/list", V2ListHandler)
+	handleUser("/v2/slide/search", V2SearchHandler)
+	handleUser("/v2/slide/details", V2DetailsHandler)
+	handleUser("/v2/slide/rename", V2RenameHandler)
+	handleUser("/v2/slide/swap", V2SwapHandler)
+	handleUser("/v2/slide/movepage", V2MovePageHandler)
+	handleUser("/v2/slide/setorder", V2SetOrderHandler)
+	handleUser("/v2/slide/duplicate", V2DuplicateHandler)
+	handleUser("/v2/slide/batch", V2BatchHandler)
+
+	handleUser("/v2/slide/setpage", V2SetPageHandler)
+	handleUser("/v2/slide/getpage", V2GetPageHandler)
+
+	handleUser("/v2/slide/revisions", V2RevisionsHandler)
+	handleUser("/v2/slide/getrevision", V2GetRevisionHandler)
+	handleUser("/v2/slide/restorerevision", V2RestoreRevisionHandler)
+
+	handleUser("/v2/slide/delete", V2DeleteSlideHandler)
+	handleUser("/v2/slide/deleteall", V2DeleteAllHandler)
+	handleUser("/v2/slide/deletepage", V2DeletePageHandler)
+
+	handleUser("/v2/slide/trash", V2TrashHandler)
+	handleUser("/v2/slide/restore", V2RestoreHandler)
+	handleUser("/v2/slide/emptytrash", V2EmptyTrashHandler)
+	handleUser("/v2/slide/usage", V2UsageHandler)
+
+	handleUser("/v2/slide/share", V2ShareHandler)
+	handleUser("/v2/slide/unshare", V2UnshareHandler)
+	handleUser("/v2/slide/grants", V2GrantsHandler)
+	handleUser("/v2/slide/createlink", V2CreateLinkHandler)
+	handleUser("/v2/slide/links", V2LinksHandler)
+	handleUser("/v2/slide/revokelink", V2RevokeLinkHandler)
+
+	handleApp("/internal/slide/details", InternalDetailsHandler)
+	handleApp("/internal/slide/getpage", InternalGetPageHandler)
+	handleApp("/internal/slide/list", InternalListHandler)
+
+	handle("/dapr/subscribe", DaprSubscribeHandler)
+	handle("/events/session-revoked", SessionRevokedHandler)
+
+	handleRest("/slides")
+	handleRest("/slides/")
+	handleRest("/slides:search")
+	handleRest("/trash")
+	handleRest("/trash/")
+	handleRest("/usage")
+
+	return routes
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Route of the session revocation events delivered by Dapr pubsub.
+const sessionRevokedPath string = "/events/session-revoked"
+
+// Subscription returned to Dapr by /dapr/subscribe.
+type DaprSubscription struct {
+	PubsubName string `json:"pubsubname"`
+	Topic      string `json:"topic"`
+	Route      string `json:"route"`
+}
+
+// Event published by the token manager when the session is revoked.
+// Either token or user_id is set. With user_id, all sessions of the user are revoked.
+type SessionRevokedEvent struct {
+	Token  string `json:"token,omitempty"`
+	UserId string `json:"user_id,omitempty"`
+}
+
+// CloudEvent of the session revocation.
+type SessionRevokedCloudEvent struct {
+	Data SessionRevokedEvent `json:"data"`
+}
+
+// Response to Dapr. `SUCCESS` or `DROP`.
+type DaprEventResponse struct {
+	Status string `json:"status"`
+}
+
+// Returns the pubsub subscriptions of this app.
+// Dapr calls it at startup.
+// The session revocation events are subscribed only if APP_API_TOKEN is set to authenticate them.
+func DaprSubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	subscriptions := []DaprSubscription{}
+	if len(tokenPubsubName) != 0 && tokenCache != nil && len(appAPIToken) != 0 {
+		topic := tokenRevokedTopic
+		if len(topic) == 0 {
+			topic = "session-revoked"
+		}
+		subscriptions = append(subscriptions, DaprSubscription{
+			PubsubName: tokenPubsubName,
+			Topic:      topic,
+			Route:      sessionRevokedPath,
+		})
+	}
+	writeJSON(w, http.StatusOK, subscriptions)
+}
+
+// Remove the revoked session from the token cache.
+// The event must have APP_API_TOKEN, because each event clears the cached verifications.
+// The events are rejected if APP_API_TOKEN is not set.
+func SessionRevokedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, []string{http.MethodPost})
+		return
+	}
+	if len(appAPIToken) == 0 {
+		writeError(w, http.StatusServiceUnavailable, "events_disabled", fmt.Errorf("the events are disabled without APP_API_TOKEN"))
+		return
+	}
+	token := r.Header.Get("dapr-api-token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(appAPIToken)) != 1 {
+		writeError(w, http.StatusUnauthorized, "unauthorized", fmt.Errorf("invalid app api token"))
+		return
+	}
+
+	event := SessionRevokedCloudEvent{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&event); err != nil || (len(event.Data.Token) == 0 && len(event.Data.UserId) == 0) {
+		// The broken event is never delivered successfully, so Dapr must not retry it.
+		writeJSON(w, http.StatusOK, DaprEventResponse{Status: "DROP"})
+		return
+	}
+
+	if tokenCache != nil {
+		if len(event.Data.Token) != 0 {
+			tokenCache.RevokeToken(event.Data.Token)
+		}
+		if len(event.Data.UserId) != 0 {
+			tokenCache.RevokeUser(event.Data.UserId)
+		}
+	}
+	writeJSON(w, http.StatusOK, DaprEventResponse{Status: "SUCCESS"})
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+// Authenticator that counts the verifications.
+type countingAuthenticator struct {
+	count int
+}
+
+func (a *countingAuthenticator) Authenticate(ctx context.Context, r *http.Request) (string, error) {
+	a.count++
+	return "user", nil
+}
+
+func TestSessionRevokedHandler(t *testing.T) {
+	defer func(token string, pubsub string, cache *auth.TokenCache) {
+		appAPIToken = token
+		tokenPubsubName = pubsub
+		tokenCache = cache
+	}(appAPIToken, tokenPubsubName, tokenCache)
+	tokenPubsubName = "pubsub"
+
+	cases := []struct {
+		name        string
+		token       string
+		headerToken string
+		status      int
+		subscribed  bool
+		reverified  bool
+	}{
+		{name: "disabled", headerToken: "", status: http.StatusServiceUnavailable},
+		{name: "invalid token", token: "secret", headerToken: "wrong", status: http.StatusUnauthorized, subscribed: true},
+		{name: "revoked", token: "secret", headerToken: "secret", status: http.StatusOK, subscribed: true, reverified: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			appAPIToken = c.token
+			tokenCache = auth.NewTokenCache(10, time.Minute)
+			counter := &countingAuthenticator{}
+			cached := auth.NewCachedAuthenticator(counter, tokenCache)
+			authenticate := func() {
+				r := httptest.NewRequest(http.MethodGet, "/slides", nil)
+				r.Header.Set("Authorization", "Bearer session")
+				if _, err := cached.Authenticate(r.Context(), r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			authenticate()
+
+			w := httptest.NewRecorder()
+			DaprSubscribeHandler(w, httptest.NewRequest(http.MethodGet, "/dapr/subscribe", nil))
+			subscriptions := []DaprSubscription{}
+			if err := json.NewDecoder(w.Body).Decode(&subscriptions); err != nil {
+				t.Fatal(err)
+			}
+			if (len(subscriptions) != 0) != c.subscribed {
+				t.Errorf("subscriptions: got %v, want subscribed %v", subscriptions, c.subscribed)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, sessionRevokedPath, strings.NewReader(`{"data": {"token": "session"}}`))
+			r.Header.Set("dapr-api-token", c.headerToken)
+			w = httptest.NewRecorder()
+			SessionRevokedHandler(w, r)
+			if w.Code != c.status {
+				t.Errorf("status: got %d, want %d", w.Code, c.status)
+			}
+
+			// The token is verified again only if the event is accepted.
+			authenticate()
+			if reverified := counter.count == 2; reverified != c.reverified {
+				t.Errorf("reverified: got %v, want %v", reverified, c.reverified)
+			}
+		})
+	}
+}
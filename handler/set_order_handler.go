@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+func SetOrderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headerData, err := networkUtils.GetHeader(w, r)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	slideId, err := networkUtils.PickValue("SlideID", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+	// Comma separated page ids.
+	order, err := networkUtils.PickValue("Order", headerData, w)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	pageIds := []string{}
+	if len(order) != 0 {
+		pageIds = strings.Split(order, ",")
+	}
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	if err := slideManager.SetPageOrder(slideId, pageIds); err != nil {
+		errorResponse(w, err)
+		return
+	}
+}
@@ -2,18 +2,30 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"mime"
 	"net/http"
+	"strings"
 
 	networkUtils "github.com/hello-slide/network-util"
-	"github.com/hello-slide/slide-manager/slide"
-	_storage "github.com/hello-slide/slide-manager/storage"
-	"github.com/hello-slide/slide-manager/utils"
+	"github.com/hello-slide/slide-manager/auth"
 )
 
+// Write page data.
+// With the json body, the page data is the `Data` value.
+// With `Content-Type: application/octet-stream`, the request body is the page data and it is streamed to the storage,
+// and the ids are the `SlideID` and `PageID` url query.
+// If-Match makes the write fail with 412 when the page has been changed since the ETag.
 func SetPageHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/octet-stream" {
+		setPageStream(ctx, w, r)
+		return
+	}
+
 	headerData, err := networkUtils.GetHeader(w, r)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
@@ -35,24 +47,49 @@ func SetPageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userId, err := utils.GetSessonToken(ctx, client, w, r, tokenManagerName, url, "/slide/setpage")
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
 	}
-	if len(userId) == 0 {
+	if err := slideManager.SetPageFrom(strings.NewReader(data), int64(len(data)), slideId, pageId, ifMatchETag(r), storageOp); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	if pageInfo, err := slideManager.StatPage(slideId, pageId, storageOp); err == nil {
+		setValidators(w, pageInfo.ETag, pageInfo.ModTime)
+	}
+}
+
+// Write page data in the request body.
+func setPageStream(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		methodNotAllowed(w, []string{http.MethodPost, http.MethodPut})
+		return
+	}
+	slideId := r.URL.Query().Get("SlideID")
+	pageId := r.URL.Query().Get("PageID")
+	if len(slideId) == 0 || len(pageId) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_input", fmt.Errorf("SlideID and PageID are required"))
 		return
 	}
 
-	slideManager := slide.NewSlideManager(ctx, &client, userId)
-	storageClient, err := _storage.CreateClient(ctx)
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	storageOp, err := newBlobStore(ctx)
 	if err != nil {
 		networkUtils.ErrorResponse(w, 1, err)
 		return
 	}
-	storageOp := _storage.NewStorageOp(ctx, *storageClient, "page-data")
-	if err := slideManager.SetPage([]byte(data), slideId, pageId, *storageOp); err != nil {
-		networkUtils.ErrorResponse(w, 1, err)
+	if err := slideManager.SetPageFrom(r.Body, r.ContentLength, slideId, pageId, ifMatchETag(r), storageOp); err != nil {
+		errorResponse(w, err)
 		return
 	}
+	if pageInfo, err := slideManager.StatPage(slideId, pageId, storageOp); err == nil {
+		setValidators(w, pageInfo.ETag, pageInfo.ModTime)
+	}
 }
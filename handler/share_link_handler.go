@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+// Header of the password of the share link.
+const sharePasswordHeader string = "X-Share-Password"
+
+// Open the slide by the share link without the session.
+// `/share/{token}` returns the slide details, and `/share/{token}/pages/{page_id}` streams the page data.
+// The password of the link is sent in `X-Share-Password` header.
+func ShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, []string{http.MethodGet})
+		return
+	}
+	token, pageId, ok := parseSharePath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Errorf("not found"))
+		return
+	}
+
+	// The link is not of the user, so the slide manager has no user.
+	slideManager := newSlideManager(ctx, "")
+	password := r.Header.Get(sharePasswordHeader)
+	// The link may be revoked, so the response must not be reused.
+	w.Header().Set("Cache-Control", "no-store")
+
+	if len(pageId) == 0 {
+		linkedSlide, err := slideManager.ReadLink(token, password)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &ShareLinkResponse{
+			Slide: linkedSlide.Slide,
+		})
+		return
+	}
+
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	reader, pageInfo, err := slideManager.OpenLinkPage(token, password, pageId, storageOp)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	writeStream(w, http.StatusOK, &pageStream{reader: reader, info: pageInfo})
+}
+
+// Returns the token and the page id of `/share/{token}` or `/share/{token}/pages/{page_id}`.
+// The page id is empty for the slide.
+func parseSharePath(path string) (string, string, bool) {
+	segments := strings.Split(strings.TrimPrefix(path, "/share/"), "/")
+	for _, segment := range segments {
+		if len(segment) == 0 {
+			return "", "", false
+		}
+	}
+	switch {
+	case len(segments) == 1:
+		return segments[0], "", true
+	case len(segments) == 3 && segments[1] == "pages":
+		return segments[0], segments[2], true
+	}
+	return "", "", false
+}
+
+// Returns the rate limit keys of the share link: the client, and the link if the request has a password.
+// The client is limited so that it can not try many links.
+// The link is limited only for the password attempts so that its password can not be guessed from many clients,
+// and the viewers of the link without a password do not share one bucket.
+func shareLinkKeys(r *http.Request) []string {
+	keys := []string{clientKey(r)}
+	if len(r.Header.Get(sharePasswordHeader)) == 0 {
+		return keys
+	}
+	if token, _, ok := parseSharePath(r.URL.Path); ok {
+		keys = append(keys, strings.Join([]string{"link", slide.LinkId(token)}, ":"))
+	}
+	return keys
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hello-slide/slide-manager/ratelimit"
+)
+
+func TestParseSharePath(t *testing.T) {
+	cases := []struct {
+		path   string
+		token  string
+		pageId string
+		ok     bool
+	}{
+		{path: "/share/token", token: "token", ok: true},
+		{path: "/share/token/pages/page", token: "token", pageId: "page", ok: true},
+		{path: "/share/", ok: false},
+		{path: "/share/token/", ok: false},
+		{path: "/share/token/pages", ok: false},
+		{path: "/share/token/pages/", ok: false},
+		{path: "/share/token/other/page", ok: false},
+		{path: "/share/token/pages/page/data", ok: false},
+	}
+	for _, c := range cases {
+		token, pageId, ok := parseSharePath(c.path)
+		if token != c.token || pageId != c.pageId || ok != c.ok {
+			t.Errorf("%s: got (%q, %q, %v), want (%q, %q, %v)", c.path, token, pageId, ok, c.token, c.pageId, c.ok)
+		}
+	}
+}
+
+func TestRateLimitShareLink(t *testing.T) {
+	setTestRateLimits(t, map[string]ratelimit.Limit{"/share/": {Rate: 0.001, Burst: 2}})
+
+	handler := RateLimitShareLink("/share/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(path string, remoteAddr string, password string) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set(sharePasswordHeader, password)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	cases := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		password   string
+		status     int
+	}{
+		{name: "viewers of the link do not share the bucket", path: "/share/a", remoteAddr: "192.0.2.1:1000", status: http.StatusOK},
+		{name: "another viewer", path: "/share/a", remoteAddr: "192.0.2.2:1000", status: http.StatusOK},
+		{name: "third viewer", path: "/share/a/pages/page", remoteAddr: "192.0.2.3:1000", status: http.StatusOK},
+		{name: "password attempt", path: "/share/p", remoteAddr: "192.0.2.4:1000", password: "1", status: http.StatusOK},
+		{name: "password attempt from another client", path: "/share/p/pages/page", remoteAddr: "192.0.2.5:1000", password: "2", status: http.StatusOK},
+		{name: "password attempts are limited by the link", path: "/share/p", remoteAddr: "192.0.2.6:1000", password: "3", status: http.StatusTooManyRequests},
+		{name: "another link from the same client", path: "/share/b", remoteAddr: "192.0.2.1:2000", status: http.StatusOK},
+		{name: "client is limited", path: "/share/c", remoteAddr: "192.0.2.1:3000", status: http.StatusTooManyRequests},
+	}
+	for _, c := range cases {
+		if status := request(c.path, c.remoteAddr, c.password); status != c.status {
+			t.Errorf("%s: got %d, want %d", c.name, status, c.status)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+)
+
+// Get the usage of the user against the quotas.
+func UsageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userId := auth.UserId(r.Context())
+
+	slideManager := newSlideManager(ctx, userId)
+	usage, err := slideManager.GetUsage()
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+
+	usageJson, err := json.Marshal(usage)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(usageJson)
+}
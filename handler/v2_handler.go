@@ -0,0 +1,493 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	networkUtils "github.com/hello-slide/network-util"
+	"github.com/hello-slide/slide-manager/auth"
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+// Max size of the json request body of the v2 API.
+const maxRequestBodySize int64 = 8 << 20
+
+// Request of the v2 API.
+type v2Request interface {
+	// Run the operation of the request and returns the response.
+	// If the response is nil, 204 No Content is returned.
+	run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error)
+}
+
+// Create handler of the v2 API.
+// The json request body is decoded to the request created by newRequest.
+func v2Handler(newRequest func() v2Request) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, []string{http.MethodPost})
+			return
+		}
+
+		serveRequest(w, r, http.StatusOK, func() (v2Request, error) {
+			request := newRequest()
+			return request, decodeJSON(w, r, request)
+		})
+	}
+}
+
+// Parse the request and run it as the authenticated user.
+//
+// Arguments:
+// - w: http writer.
+// - r: http requests.
+// - httpStatus: http status of the successful response.
+// - parse: returns the request parsed from r.
+func serveRequest(w http.ResponseWriter, r *http.Request, httpStatus int, parse func() (v2Request, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	request, err := parse()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_input", err)
+		return
+	}
+	if request, ok := request.(rawRequest); ok {
+		request.setRequest(r)
+	}
+
+	slideManager := newSlideManager(ctx, auth.UserId(r.Context()))
+	response, err := request.run(ctx, slideManager)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	writeResponse(w, r, httpStatus, response)
+}
+
+// Write the response of the request.
+// The page data is streamed, and the conditional response is 304 if the client has the current one.
+func writeResponse(w http.ResponseWriter, r *http.Request, httpStatus int, response interface{}) {
+	if stream, ok := response.(*pageStream); ok {
+		if checkNotModified(w, r, stream.info.ETag, stream.info.ModTime) {
+			stream.reader.Close()
+			return
+		}
+		writeStream(w, httpStatus, stream)
+		return
+	}
+	if conditional, ok := response.(*conditionalResponse); ok {
+		// The response of the write only has the validators of the new version.
+		if conditional.response == nil {
+			setValidators(w, conditional.etag, conditional.lastModified)
+		} else if checkNotModified(w, r, conditional.etag, conditional.lastModified) {
+			return
+		}
+		response = conditional.response
+	}
+	writeJSON(w, httpStatus, response)
+}
+
+// Write 405 Method Not Allowed with the allowed methods.
+func methodNotAllowed(w http.ResponseWriter, methods []string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Errorf("method not allowed"))
+}
+
+// Decode the json request body.
+// The empty body is decoded as `{}`.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		return slide.InvalidInput("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// Write the response as json.
+// If the response is nil, writes 204 No Content.
+func writeJSON(w http.ResponseWriter, httpStatus int, response interface{}) {
+	if response == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		networkUtils.ErrorResponse(w, 1, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	w.Write(body)
+}
+
+var V2CreateHandler = v2Handler(func() v2Request { return &CreateRequest{} })
+var V2CreatePageHandler = v2Handler(func() v2Request { return &CreatePageRequest{} })
+var V2ListHandler = v2Handler(func() v2Request { return &ListRequest{} })
+var V2SearchHandler = v2Handler(func() v2Request { return &SearchRequest{} })
+var V2BatchHandler = v2Handler(func() v2Request { return &BatchRequest{} })
+var V2DetailsHandler = v2Handler(func() v2Request { return &SlideRequest{} })
+var V2RenameHandler = v2Handler(func() v2Request { return &RenameRequest{} })
+var V2SwapHandler = v2Handler(func() v2Request { return &SwapRequest{} })
+var V2MovePageHandler = v2Handler(func() v2Request { return &MovePageRequest{} })
+var V2SetOrderHandler = v2Handler(func() v2Request { return &SetOrderRequest{} })
+var V2DuplicateHandler = v2Handler(func() v2Request { return &DuplicateRequest{} })
+var V2SetPageHandler = v2Handler(func() v2Request { return &SetPageRequest{} })
+var V2GetPageHandler = v2Handler(func() v2Request { return &PageRequest{} })
+var V2RevisionsHandler = v2Handler(func() v2Request { return &revisionsRequest{} })
+var V2GetRevisionHandler = v2Handler(func() v2Request { return &RevisionRequest{} })
+var V2RestoreRevisionHandler = v2Handler(func() v2Request { return &restoreRevisionRequest{} })
+var V2DeleteSlideHandler = v2Handler(func() v2Request { return &deleteSlideRequest{} })
+var V2DeleteAllHandler = v2Handler(func() v2Request { return &deleteAllRequest{} })
+var V2DeletePageHandler = v2Handler(func() v2Request { return &deletePageRequest{} })
+var V2TrashHandler = v2Handler(func() v2Request { return &trashRequest{} })
+var V2RestoreHandler = v2Handler(func() v2Request { return &RestoreRequest{} })
+var V2EmptyTrashHandler = v2Handler(func() v2Request { return &emptyTrashRequest{} })
+var V2ShareHandler = v2Handler(func() v2Request { return &ShareRequest{} })
+var V2UnshareHandler = v2Handler(func() v2Request { return &UnshareRequest{} })
+var V2GrantsHandler = v2Handler(func() v2Request { return &grantsRequest{} })
+var V2CreateLinkHandler = v2Handler(func() v2Request { return &CreateLinkRequest{} })
+var V2LinksHandler = v2Handler(func() v2Request { return &linksRequest{} })
+var V2RevokeLinkHandler = v2Handler(func() v2Request { return &RevokeLinkRequest{} })
+var V2UsageHandler = v2Handler(func() v2Request { return &usageRequest{} })
+
+// Requests that have the same body as another request but run a different operation.
+type deleteAllRequest EmptyRequest
+type trashRequest EmptyRequest
+type emptyTrashRequest EmptyRequest
+type usageRequest EmptyRequest
+type deleteSlideRequest SlideRequest
+type deletePageRequest PageRequest
+type revisionsRequest PageRequest
+type restoreRevisionRequest RevisionRequest
+type grantsRequest SlideRequest
+type linksRequest SlideRequest
+
+func (req *CreateRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("title", req.Title); err != nil {
+		return nil, err
+	}
+	slideId, err := slideManager.Create(req.Title)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateResponse{SlideId: slideId}, nil
+}
+
+func (req *CreatePageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_type", req.PageType); err != nil {
+		return nil, err
+	}
+	return slideManager.CreatePage(req.SlideId, req.PageType)
+}
+
+func (req *ListRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	options, err := req.options()
+	if err != nil {
+		return nil, err
+	}
+	return slideManager.ListSlides(options)
+}
+
+func (req *SearchRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("query", req.Query); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slideManager.Search(req.Query, req.Limit, storageOp)
+}
+
+func (req *BatchRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	atomic := true
+	switch req.Mode {
+	case "", "atomic":
+	case "best_effort":
+		atomic = false
+	default:
+		return nil, slide.InvalidInput("mode must be atomic or best_effort")
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := slideManager.Batch(req.SlideId, req.Operations, atomic, storageOp)
+	if err != nil {
+		return nil, err
+	}
+	response := &BatchResponse{
+		Applied: result.Applied,
+		Results: []BatchOperationResponse{},
+	}
+	for _, operationResult := range result.Results {
+		operationResponse := BatchOperationResponse{
+			Op:     operationResult.Op,
+			Status: operationResult.Status,
+			PageId: operationResult.PageId,
+		}
+		if operationResult.Err != nil {
+			_, operationResponse.Code = errorStatus(operationResult.Err)
+			operationResponse.Error = operationResult.Err.Error()
+		}
+		response.Results = append(response.Results, operationResponse)
+	}
+	return response, nil
+}
+
+func (req *SlideRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	slideData, etag, err := slideManager.GetSlideDetailsWithETag(req.SlideId)
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalResponse{
+		response:     slideData,
+		etag:         etag,
+		lastModified: slideData.ChangeTime(),
+	}, nil
+}
+
+func (req *RenameRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "new_name", req.NewName); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.Rename(req.SlideId, req.NewName)
+}
+
+func (req *SwapRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.SwapPage(req.SlideId, req.Origin, req.Target)
+}
+
+func (req *MovePageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.MovePage(req.SlideId, req.PageId, req.Index)
+}
+
+func (req *SetOrderRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.SetPageOrder(req.SlideId, req.PageIds)
+}
+
+func (req *DuplicateRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "title", req.Title); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slideId, err := slideManager.Duplicate(req.SlideId, req.Title, storageOp)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateResponse{SlideId: slideId}, nil
+}
+
+func (req *SetPageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := strings.NewReader(req.Data)
+	if err := slideManager.SetPageFrom(data, data.Size(), req.SlideId, req.PageId, req.ifMatch, storageOp); err != nil {
+		return nil, err
+	}
+	return pageWritten(slideManager, req.SlideId, req.PageId, storageOp), nil
+}
+
+func (req *SetPageRequest) setRequest(r *http.Request) {
+	req.ifMatch = ifMatchETag(r)
+}
+
+func (req *PageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader, pageInfo, err := slideManager.OpenPage(req.SlideId, req.PageId, storageOp)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalResponse{
+		response: &PageResponse{
+			SlideId: req.SlideId,
+			PageId:  req.PageId,
+			Data:    string(data),
+		},
+		etag:         pageInfo.ETag,
+		lastModified: pageInfo.ModTime,
+	}, nil
+}
+
+func (req *revisionsRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	return slideManager.GetRevisions(req.SlideId, req.PageId)
+}
+
+func (req *RevisionRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := slideManager.GetRevision(req.SlideId, req.PageId, req.Revision, storageOp)
+	if err != nil {
+		return nil, err
+	}
+	return &RevisionResponse{
+		SlideId:  req.SlideId,
+		PageId:   req.PageId,
+		Revision: req.Revision,
+		Data:     string(data),
+	}, nil
+}
+
+func (req *restoreRevisionRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, slideManager.RestoreRevision(req.SlideId, req.PageId, req.Revision, storageOp)
+}
+
+func (req *deleteSlideRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, slideManager.Delete(req.SlideId, storageOp)
+}
+
+func (req *deleteAllRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, slideManager.DeleteAll(storageOp)
+}
+
+func (req *deletePageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "page_id", req.PageId); err != nil {
+		return nil, err
+	}
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, slideManager.DeletePage(req.SlideId, req.PageId, storageOp)
+}
+
+func (req *trashRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slideManager.GetTrash(storageOp)
+}
+
+func (req *RestoreRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("trash_id", req.TrashId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.RestoreTrash(req.TrashId)
+}
+
+func (req *emptyTrashRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	storageOp, err := newBlobStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nil, slideManager.EmptyTrash(storageOp)
+}
+
+func (req *usageRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	return slideManager.GetUsage()
+}
+
+func (req *ShareRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "user_id", req.UserId, "role", req.Role); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.Share(req.SlideId, req.UserId, slide.Role(req.Role))
+}
+
+func (req *UnshareRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "user_id", req.UserId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.Unshare(req.SlideId, req.UserId)
+}
+
+func (req *grantsRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	return slideManager.GetGrants(req.SlideId)
+}
+
+func (req *CreateLinkRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	return slideManager.CreateLink(req.SlideId, time.Duration(req.ExpiresIn)*time.Second, req.Password)
+}
+
+func (req *linksRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId); err != nil {
+		return nil, err
+	}
+	return slideManager.GetLinks(req.SlideId)
+}
+
+func (req *RevokeLinkRequest) run(ctx context.Context, slideManager *slide.SlideManager) (interface{}, error) {
+	if err := requireFields("slide_id", req.SlideId, "link_id", req.LinkId); err != nil {
+		return nil, err
+	}
+	return nil, slideManager.RevokeLink(req.SlideId, req.LinkId)
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	_url "net/url"
+	"strconv"
+
+	"github.com/hello-slide/slide-manager/slide"
+)
+
+// Request bodies and responses of the v2 API.
+
+type CreateRequest struct {
+	Title string `json:"title"`
+}
+
+type ListRequest struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+	// `title`, `create_date` or `change_date`.
+	Sort string `json:"sort"`
+	// `asc` or `desc`.
+	Order string `json:"order"`
+	// Range of the dates formatted as `20060102150405`.
+	CreatedAfter  string `json:"created_after"`
+	CreatedBefore string `json:"created_before"`
+	ChangedAfter  string `json:"changed_after"`
+	ChangedBefore string `json:"changed_before"`
+}
+
+type CreateResponse struct {
+	SlideId string `json:"slide_id"`
+}
+
+type SlideRequest struct {
+	SlideId string `json:"slide_id"`
+}
+
+type CreatePageRequest struct {
+	SlideId  string `json:"slide_id"`
+	PageType string `json:"page_type"`
+}
+
+type PageRequest struct {
+	SlideId string `json:"slide_id"`
+	PageId  string `json:"page_id"`
+}
+
+type SetPageRequest struct {
+	SlideId string `json:"slide_id"`
+	PageId  string `json:"page_id"`
+	Data    string `json:"data"`
+	// ETag in If-Match header.
+	ifMatch string
+}
+
+type PageResponse struct {
+	SlideId string `json:"slide_id"`
+	PageId  string `json:"page_id"`
+	Data    string `json:"data"`
+}
+
+type RenameRequest struct {
+	SlideId string `json:"slide_id"`
+	NewName string `json:"new_name"`
+}
+
+type SwapRequest struct {
+	SlideId string `json:"slide_id"`
+	Origin  int    `json:"origin"`
+	Target  int    `json:"target"`
+}
+
+type MovePageRequest struct {
+	SlideId string `json:"slide_id"`
+	PageId  string `json:"page_id"`
+	Index   int    `json:"index"`
+}
+
+type SetOrderRequest struct {
+	SlideId string   `json:"slide_id"`
+	PageIds []string `json:"page_ids"`
+}
+
+type DuplicateRequest struct {
+	SlideId string `json:"slide_id"`
+	Title   string `json:"title"`
+}
+
+type RevisionRequest struct {
+	SlideId  string `json:"slide_id"`
+	PageId   string `json:"page_id"`
+	Revision int    `json:"revision"`
+}
+
+type RevisionResponse struct {
+	SlideId  string `json:"slide_id"`
+	PageId   string `json:"page_id"`
+	Revision int    `json:"revision"`
+	Data     string `json:"data"`
+}
+
+type SearchRequest struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type BatchRequest struct {
+	SlideId string `json:"slide_id"`
+	// `atomic` (default) applies all operations or nothing,
+	// and `best_effort` skips the failed operations.
+	Mode       string                 `json:"mode"`
+	Operations []slide.BatchOperation `json:"operations"`
+}
+
+type BatchResponse struct {
+	Applied bool                     `json:"applied"`
+	Results []BatchOperationResponse `json:"results"`
+}
+
+type BatchOperationResponse struct {
+	Op string `json:"op"`
+	// `ok`, `failed` or `skipped`.
+	Status string `json:"status"`
+	PageId string `json:"page_id,omitempty"`
+	// Error code and message of the failed operation.
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type RestoreRequest struct {
+	TrashId string `json:"trash_id"`
+}
+
+type ShareRequest struct {
+	SlideId string `json:"slide_id"`
+	UserId  string `json:"user_id"`
+	// `viewer` or `editor`.
+	Role string `json:"role"`
+}
+
+type UnshareRequest struct {
+	SlideId string `json:"slide_id"`
+	UserId  string `json:"user_id"`
+}
+
+type CreateLinkRequest struct {
+	SlideId string `json:"slide_id"`
+	// Seconds until the link expires. It does not expire if 0.
+	ExpiresIn int `json:"expires_in"`
+	// Password required to open the link. Not required if empty.
+	Password string `json:"password"`
+}
+
+type RevokeLinkRequest struct {
+	SlideId string `json:"slide_id"`
+	LinkId  string `json:"link_id"`
+}
+
+// Slide opened by the share link.
+// The page data is downloaded from `/share/{token}/pages/{page_id}`.
+type ShareLinkResponse struct {
+	Slide *slide.SlideData `json:"slide"`
+}
+
+// Request without arguments.
+type EmptyRequest struct{}
+
+// Returns the options of slide.ListSlides.
+func (req *ListRequest) options() (slide.ListOptions, error) {
+	descending := false
+	switch req.Order {
+	case "", "asc":
+	case "desc":
+		descending = true
+	default:
+		return slide.ListOptions{}, slide.InvalidInput("order must be asc or desc")
+	}
+
+	return slide.ListOptions{
+		Cursor:        req.Cursor,
+		Limit:         req.Limit,
+		SortBy:        req.Sort,
+		Descending:    descending,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		ChangedAfter:  req.ChangedAfter,
+		ChangedBefore: req.ChangedBefore,
+	}, nil
+}
+
+// Returns the list request from the url query such as `?limit=20&sort=title`.
+func listRequestFromQuery(query _url.Values) (*ListRequest, error) {
+	request := &ListRequest{
+		Cursor:        query.Get("cursor"),
+		Sort:          query.Get("sort"),
+		Order:         query.Get("order"),
+		CreatedAfter:  query.Get("created_after"),
+		CreatedBefore: query.Get("created_before"),
+		ChangedAfter:  query.Get("changed_after"),
+		ChangedBefore: query.Get("changed_before"),
+	}
+	if limit := query.Get("limit"); len(limit) != 0 {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, slide.InvalidInput("limit must be a number")
+		}
+		request.Limit = limitInt
+	}
+	return request, nil
+}
+
+// Returns an error if any of the fields is empty.
+//
+// Arguments:
+// - fields: pairs of the json key and its value.
+func requireFields(fields ...string) error {
+	for index := 0; index+1 < len(fields); index += 2 {
+		if len(fields[index+1]) == 0 {
+			return slide.InvalidInput("%s is required", fields[index])
+		}
+	}
+	return nil
+}
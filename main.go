@@ -14,29 +14,28 @@ func init() {
 	if err := handler.InitClient(); err != nil {
 		panic(err)
 	}
+	if err := handler.InitAuth(); err != nil {
+		panic(err)
+	}
+	if err := handler.InitState(); err != nil {
+		panic(err)
+	}
+	if err := handler.InitRateLimit(); err != nil {
+		panic(err)
+	}
 	if err := handler.InitStorage(ctx); err != nil {
 		panic(err)
 	}
+	if err := handler.StartTrashPurge(); err != nil {
+		panic(err)
+	}
 }
 
 func main() {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", handler.RootHandler)
-
-	mux.HandleFunc("/slide/create", handler.CreateHandler)
-	mux.HandleFunc("/slide/createpage", handler.CreatePageHandler)
-
-	mux.HandleFunc("/slide/list", handler.ListHandler)
-	mux.HandleFunc("/slide/details", handler.DetailsHandler)
-	mux.HandleFunc("/slide/rename", handler.RenameHandler)
-	mux.HandleFunc("/slide/swap", handler.SwapHandler)
-
-	mux.HandleFunc("/slide/setpage", handler.SetPageHandler)
-	mux.HandleFunc("/slide/getpage", handler.GetPageHandler)
-
-	mux.HandleFunc("/slide/delete", handler.DeleteSlideHandler)
-	mux.HandleFunc("/slide/deleteall", handler.DeleteAllHandler)
-	mux.HandleFunc("/slide/deletepage", handler.DeletePageHandler)
+	for _, route := range handler.Routes() {
+		mux.HandleFunc(route.Path, route.Handler)
+	}
 
 	handler := networkUtils.CorsConfig.Handler(mux)
 
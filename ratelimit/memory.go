@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Limiter that keeps the buckets in the process.
+// Each replica limits the requests independently.
+// The least recently used bucket is removed when the limiter is full.
+type MemoryLimiter struct {
+	mutex      sync.Mutex
+	maxBuckets int
+	buckets    map[string]*list.Element
+	// The front is the most recently used.
+	order *list.List
+}
+
+type memoryBucket struct {
+	bucket
+	key string
+}
+
+// Create limiter in the process.
+//
+// Arguments:
+// - maxBuckets: max number of the buckets.
+func NewMemoryLimiter(maxBuckets int) *MemoryLimiter {
+	return &MemoryLimiter{
+		maxBuckets: maxBuckets,
+		buckets:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(key string, limit Limit) (bool, time.Duration, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	element, ok := l.buckets[key]
+	if ok {
+		l.order.MoveToFront(element)
+	} else {
+		for len(l.buckets) > 0 && len(l.buckets) >= l.maxBuckets {
+			l.removeElement(l.order.Back())
+		}
+		element = l.order.PushFront(&memoryBucket{
+			bucket: *newBucket(limit, now),
+			key:    key,
+		})
+		l.buckets[key] = element
+	}
+
+	allowed, retryAfter := element.Value.(*memoryBucket).take(limit, now)
+	return allowed, retryAfter, nil
+}
+
+func (l *MemoryLimiter) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.buckets, element.Value.(*memoryBucket).key)
+}
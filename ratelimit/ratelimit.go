@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit of the token bucket.
+type Limit struct {
+	// Tokens added per second. One request takes one token.
+	Rate float64
+	// Max number of tokens, that is the requests allowed at once.
+	Burst int
+}
+
+// Limiter of the requests by the token bucket of each key.
+type Limiter interface {
+	// Take one token from the bucket of key.
+	// If the bucket is empty, returns false and the period until a token is added.
+	Allow(key string, limit Limit) (bool, time.Duration, error)
+}
+
+// Token bucket. It is full when it is created.
+type bucket struct {
+	Tokens float64 `json:"tokens"`
+	// Unix time in nanoseconds when Tokens was calculated.
+	Updated int64 `json:"updated"`
+}
+
+// Returns a full bucket of the limit.
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{
+		Tokens:  float64(limit.burst()),
+		Updated: now.UnixNano(),
+	}
+}
+
+// Add the tokens since the last update and take one.
+// If the bucket is empty, returns false and the period until a token is added.
+func (b *bucket) take(limit Limit, now time.Time) (bool, time.Duration) {
+	elapsed := now.Sub(time.Unix(0, b.Updated)).Seconds()
+	if elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.burst()), b.Tokens+elapsed*limit.Rate)
+		b.Updated = now.UnixNano()
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return true, 0
+	}
+	if limit.Rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - b.Tokens) / limit.Rate * float64(time.Second))
+}
+
+// Returns the period until the empty bucket is refilled.
+// After that, the bucket is the same as a new one. Zero if it is never refilled.
+func (l Limit) refillTime() time.Duration {
+	if l.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(l.burst()) / l.Rate * float64(time.Second))
+}
+
+// Burst is at least one, otherwise no request is allowed.
+func (l Limit) burst() int {
+	if l.Burst < 1 {
+		return 1
+	}
+	return l.Burst
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+func TestMemoryLimiterEvictsLeastRecentlyUsed(t *testing.T) {
+	l := NewMemoryLimiter(2)
+	limit := Limit{Rate: 0.001, Burst: 1}
+
+	allow := func(key string) bool {
+		allowed, _, err := l.Allow(key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return allowed
+	}
+
+	cases := []struct {
+		key     string
+		allowed bool
+	}{
+		{key: "a", allowed: true},
+		{key: "b", allowed: true},
+		// a is the most recently used.
+		{key: "a", allowed: false},
+		// b is removed for c.
+		{key: "c", allowed: true},
+		{key: "a", allowed: false},
+		{key: "b", allowed: true},
+	}
+	for index, c := range cases {
+		if got := allow(c.key); got != c.allowed {
+			t.Errorf("%d: %s: got %v, want %v", index, c.key, got, c.allowed)
+		}
+		if len(l.buckets) > 2 || l.order.Len() != len(l.buckets) {
+			t.Fatalf("%d: %d buckets in the limiter of 2", index, len(l.buckets))
+		}
+	}
+}
+
+// State store that counts the operations.
+type countingState struct {
+	state.StateStore
+	gets       int
+	operations []state.Operation
+}
+
+func (s *countingState) Get(key string) (*state.Item, error) {
+	s.gets++
+	return s.StateStore.Get(key)
+}
+
+func (s *countingState) Transaction(operations []state.Operation) error {
+	s.operations = append(s.operations, operations...)
+	return s.StateStore.Transaction(operations)
+}
+
+func TestStateLimiter(t *testing.T) {
+	store := &countingState{StateStore: state.NewMemoryState()}
+	l := NewStateLimiter(store)
+	limit := Limit{Rate: 0.5, Burst: 2}
+
+	for index, want := range []bool{true, true, false, false} {
+		allowed, retryAfter, err := l.Allow("user|/slide/create", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != want {
+			t.Errorf("%d: got %v, want %v", index, allowed, want)
+		}
+		if !allowed && (retryAfter <= 0 || retryAfter > 2*time.Second) {
+			t.Errorf("%d: retry after %s", index, retryAfter)
+		}
+	}
+
+	// The empty bucket is known in the process, so the last request does not read the state.
+	if store.gets != 3 {
+		t.Errorf("gets: got %d, want 3", store.gets)
+	}
+	// The bucket expires when it is refilled.
+	if len(store.operations) != 2 {
+		t.Fatalf("got %d writes, want 2", len(store.operations))
+	}
+	for _, operation := range store.operations {
+		if !strings.HasPrefix(operation.Key, "rate-limit|") || operation.TTL != 4*time.Second {
+			t.Errorf("got %s with TTL %s", operation.Key, operation.TTL)
+		}
+	}
+}
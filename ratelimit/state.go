@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+// Number of attempts when another replica has changed the bucket.
+const maxStateAttempts = 5
+
+// Max number of the empty buckets remembered in the process.
+const maxDeniedBuckets = 100000
+
+// Limiter that keeps the buckets in the state store.
+// The replicas share the buckets, and each allowed request reads and writes its bucket.
+// Each bucket expires when it has been refilled, because it is the same as a new one.
+type StateLimiter struct {
+	state state.StateStore
+
+	mutex sync.Mutex
+	// Time until which the bucket is empty by the key.
+	// The other replicas only take tokens, so the request is denied without the state store until then.
+	denied map[string]time.Time
+}
+
+// Create limiter on the state store shared by the replicas.
+func NewStateLimiter(stateStore state.StateStore) *StateLimiter {
+	return &StateLimiter{
+		state:  stateStore,
+		denied: map[string]time.Time{},
+	}
+}
+
+func (l *StateLimiter) Allow(key string, limit Limit) (bool, time.Duration, error) {
+	stateKey := strings.Join([]string{"rate-limit", key}, "|")
+
+	if retryAfter, ok := l.deniedFor(stateKey, time.Now()); ok {
+		return false, retryAfter, nil
+	}
+
+	for attempt := 0; attempt < maxStateAttempts; attempt++ {
+		item, err := l.state.Get(stateKey)
+		if err != nil {
+			return false, 0, err
+		}
+
+		now := time.Now()
+		b := newBucket(limit, now)
+		if len(item.Value) != 0 {
+			if err := json.Unmarshal(item.Value, b); err != nil {
+				return false, 0, err
+			}
+		}
+
+		allowed, retryAfter := b.take(limit, now)
+		if !allowed {
+			// The empty bucket is not changed, so it is not written.
+			if limit.Rate > 0 {
+				l.deny(stateKey, now.Add(retryAfter), now)
+			}
+			return false, retryAfter, nil
+		}
+
+		value, err := json.Marshal(b)
+		if err != nil {
+			return false, 0, err
+		}
+		err = l.state.Transaction([]state.Operation{
+			{
+				Type:  state.OperationUpsert,
+				Key:   stateKey,
+				Value: value,
+				Etag:  item.Etag,
+				TTL:   limit.refillTime(),
+			},
+		})
+		if errors.Is(err, state.ErrETagMismatch) {
+			continue
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		return true, 0, nil
+	}
+	return false, 0, state.ErrETagMismatch
+}
+
+// Returns the period until a token is added if the bucket of key is known to be empty.
+func (l *StateLimiter) deniedFor(stateKey string, now time.Time) (time.Duration, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	until, ok := l.denied[stateKey]
+	if !ok {
+		return 0, false
+	}
+	if !now.Before(until) {
+		delete(l.denied, stateKey)
+		return 0, false
+	}
+	return until.Sub(now), true
+}
+
+// Remember that the bucket of key is empty until the time.
+// The passed ones are removed when it is full, and it is not remembered if it is still full.
+func (l *StateLimiter) deny(stateKey string, until time.Time, now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.denied) >= maxDeniedBuckets {
+		for key, keyUntil := range l.denied {
+			if !now.Before(keyUntil) {
+				delete(l.denied, key)
+			}
+		}
+		if len(l.denied) >= maxDeniedBuckets {
+			return
+		}
+	}
+	l.denied[stateKey] = until
+}
@@ -0,0 +1,411 @@
+package slide
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+	"github.com/hello-slide/slide-manager/utils"
+)
+
+// Status of the operation in the batch.
+const (
+	batchStatusOk      string = "ok"
+	batchStatusFailed  string = "failed"
+	batchStatusSkipped string = "skipped"
+)
+
+// Max number of operations in one batch.
+const maxBatchOperations int = 100
+
+// Page data written by set_page in the batch.
+type batchPageData struct {
+	// Index of the operation.
+	index      int
+	pageId     string
+	revisionId string
+	data       []byte
+}
+
+// Apply the operations to the slide in order.
+// The operations are applied to one copy of the slide details and it is written at once.
+// If atomic is true, no operation is applied when any of them fails.
+// Otherwise the failed operations are skipped and the others are applied.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - operations: operations to apply.
+// - atomic: all-or-nothing if true, best-effort if false.
+// - storageOp: storage op instance
+func (s *SlideManager) Batch(slideId string, operations []BatchOperation, atomic bool, storageOp storage.BlobStore) (*BatchResult, error) {
+	s, err := s.access(slideId, RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(operations) == 0 {
+		return nil, InvalidInput("operations are required")
+	}
+	if len(operations) > maxBatchOperations {
+		return nil, InvalidInput("the batch must have at most %d operations", maxBatchOperations)
+	}
+
+	// The ids are decided before applying so that they do not change when the commit is retried.
+	createdPageIds := map[int]string{}
+	for index, operation := range operations {
+		if operation.Op == "create_page" {
+			pageId, err := utils.CreateId(strings.Join([]string{slideId, strconv.Itoa(index)}, "|"))
+			if err != nil {
+				return nil, err
+			}
+			createdPageIds[index] = pageId
+		}
+	}
+
+	// The page data that can not be committed is rejected before it is uploaded.
+	// They are checked again at the commit, because the slide may be changed while uploading.
+	slideData, _, err := s.loadDetails(slideId)
+	if err != nil {
+		return nil, err
+	}
+	minUsedBytes, err := s.minBatchUsedBytes(slideId, slideData, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the page data as revisions before the commit, and the committed ones become the current page data.
+	pageData := map[int]*batchPageData{}
+	writeErrors := map[int]error{}
+	for index, operation := range operations {
+		if operation.Op != "set_page" {
+			continue
+		}
+		pageId, err := resolvePageId(operation.PageId, index, operations, createdPageIds)
+		if err == nil && int64(len(operation.Data)) > maxPageSize {
+			err = pageTooLarge()
+		}
+		if err == nil && !strings.HasPrefix(operation.PageId, "$") {
+			_, err = getIndexPage(*slideData, pageId)
+		}
+		if err == nil {
+			err = checkBytesLimit(minUsedBytes + int64(len(operation.Data)))
+		}
+		if err != nil {
+			writeErrors[index] = err
+			continue
+		}
+		revisionId, err := utils.CreateId(strings.Join([]string{pageId, strconv.Itoa(index)}, "|"))
+		if err != nil {
+			return nil, err
+		}
+		data := []byte(operation.Data)
+		if _, err := storageOp.WriteStream(s.revisionDirs(slideId, pageId), revisionId, bytes.NewReader(data)); err != nil {
+			writeErrors[index] = err
+			continue
+		}
+		pageData[index] = &batchPageData{
+			index:      index,
+			pageId:     pageId,
+			revisionId: revisionId,
+			data:       data,
+		}
+	}
+
+	var result *BatchResult
+	// Page data that is the last one written to each page.
+	var committed map[string]*batchPageData
+	isCommitted := false
+
+	err = retryOnConflict(func() error {
+		slideData, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
+
+		result = &BatchResult{
+			Results: make([]BatchOperationResult, len(operations)),
+		}
+		committed = map[string]*batchPageData{}
+		trashItems := []TrashItem{}
+		isFailed := false
+
+		usage, usageEtag, err := s.readUsage()
+		if err != nil {
+			return err
+		}
+		usedBytes := usage.Bytes
+
+		for index, operation := range operations {
+			operationResult := &result.Results[index]
+			operationResult.Op = operation.Op
+			if isFailed && atomic {
+				operationResult.Status = batchStatusSkipped
+				continue
+			}
+
+			err := writeErrors[index]
+			if err == nil {
+				err = s.applyBatchOperation(slideData, index, operations, createdPageIds, pageData, committed, &trashItems, &usedBytes, operationResult)
+			}
+			if err != nil {
+				operationResult.Status = batchStatusFailed
+				operationResult.Err = err
+				isFailed = true
+				continue
+			}
+			operationResult.Status = batchStatusOk
+		}
+		if isFailed && atomic {
+			for index := range result.Results {
+				if result.Results[index].Status == batchStatusOk {
+					result.Results[index].Status = batchStatusSkipped
+					result.Results[index].PageId = ""
+				}
+			}
+			committed = map[string]*batchPageData{}
+			return nil
+		}
+
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
+
+		extraOperations := []state.Operation{}
+		prunePaths := []string{}
+		// The stored bytes are counted exactly here, because the pruned revisions are known only at the commit.
+		usedBytes = usage.Bytes
+		for _, data := range committed {
+			revisionsOperation, paths, prunedBytes, err := s.addRevisionOperation(slideId, data.pageId, data.revisionId, len(data.data))
+			if err != nil {
+				return err
+			}
+			if pageIndex, err := getIndexPage(*slideData, data.pageId); err == nil {
+				page := &slideData.Pages[pageIndex]
+				// The page data before the revisions is no longer read.
+				if len(page.RevisionId) == 0 {
+					paths = append(paths, strings.Join([]string{"pages", s.userId, slideId, data.pageId}, "/"))
+					prunedBytes += page.Size
+				}
+				page.Size = int64(len(data.data))
+				page.RevisionId = data.revisionId
+			}
+			extraOperations = append(extraOperations, revisionsOperation)
+			prunePaths = append(prunePaths, paths...)
+			usedBytes += int64(len(data.data)) - prunedBytes
+		}
+		if usedBytes < 0 {
+			usedBytes = 0
+		}
+		// The page data overwritten in the batch or written to the deleted pages is never read.
+		for _, data := range pageData {
+			if committed[data.pageId] != data {
+				prunePaths = append(prunePaths, strings.Join(append(s.revisionDirs(slideId, data.pageId), data.revisionId), "/"))
+			}
+		}
+		if len(prunePaths) != 0 {
+			cleanupOperation, err := s.cleanupOperation(prunePaths...)
+			if err != nil {
+				return err
+			}
+			extraOperations = append(extraOperations, cleanupOperation)
+		}
+
+		if usedBytes != usage.Bytes {
+			usage.Bytes = usedBytes
+			usageOperation, err := upsertOperation(s.usageKey(), usage, usageEtag)
+			if err != nil {
+				return err
+			}
+			extraOperations = append(extraOperations, usageOperation)
+		}
+
+		if len(trashItems) != 0 {
+			trashOperation, err := s.moveToTrashOperation(trashItems...)
+			if err != nil {
+				return err
+			}
+			extraOperations = append(extraOperations, trashOperation)
+		}
+
+		searchOperation, err := s.searchIndexOperation(slideId, func(index *searchIndex) {
+			index.set(slideId, tokenize(slideData.Title))
+			for _, data := range committed {
+				index.set(pageDocumentKey(slideId, data.pageId), pageTokens(data.data))
+			}
+		})
+		if err != nil {
+			return err
+		}
+		extraOperations = append(extraOperations, searchOperation)
+
+		result.Applied = true
+		if err := s.commitDetails(slideData, etag, extraOperations...); err != nil {
+			return err
+		}
+		isCommitted = true
+		return nil
+	})
+
+	// The page data has not been committed, so nobody reads it.
+	if !isCommitted {
+		for _, data := range pageData {
+			if err := storageOp.Delete(strings.Join(append(s.revisionDirs(slideId, data.pageId), data.revisionId), "/")); err != nil {
+				log.Printf("failed to delete the page data of the batch: %v", err)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete the page data that is not current.
+	if isCommitted {
+		s.cleanupAfterCommit(storageOp)
+	}
+	return result, nil
+}
+
+// Returns the least stored bytes of the user that the batch can leave before its page data is added.
+// All stored bytes of the existing pages set in the batch are subtracted as if they were freed,
+// so the page data over the quota with this is over it in any case.
+func (s *SlideManager) minBatchUsedBytes(slideId string, slideData *SlideData, operations []BatchOperation) (int64, error) {
+	if maxStorageBytes <= 0 {
+		return 0, nil
+	}
+	usage, _, err := s.readUsage()
+	if err != nil {
+		return 0, err
+	}
+
+	usedBytes := usage.Bytes
+	seen := map[string]bool{}
+	for _, operation := range operations {
+		if operation.Op != "set_page" || seen[operation.PageId] {
+			continue
+		}
+		seen[operation.PageId] = true
+		pageIndex, err := getIndexPage(*slideData, operation.PageId)
+		if err != nil {
+			continue
+		}
+		bytes, err := s.storedPageBytes(slideId, slideData.Pages[pageIndex])
+		if err != nil {
+			return 0, err
+		}
+		usedBytes -= bytes
+	}
+	return usedBytes, nil
+}
+
+// Apply the operation to slideData.
+// usedBytes is the stored bytes of the user with the page data committed so far.
+// It does not free the revisions pruned by the commit, so the quota is checked conservatively.
+// slideData is not changed if it returns an error.
+func (s *SlideManager) applyBatchOperation(slideData *SlideData, index int, operations []BatchOperation, createdPageIds map[int]string, pageData map[int]*batchPageData, committed map[string]*batchPageData, trashItems *[]TrashItem, usedBytes *int64, result *BatchOperationResult) error {
+	operation := operations[index]
+
+	switch operation.Op {
+	case "create_page":
+		if err := checkPagesQuota(slideData); err != nil {
+			return err
+		}
+		result.PageId = createdPageIds[index]
+		slideData.NumberOfPages++
+		slideData.Pages = append(slideData.Pages, PageData{
+			PageId: createdPageIds[index],
+			Type:   operation.PageType,
+		})
+		return nil
+	case "set_page":
+		// The page id has been resolved when the page data was written.
+		pageId := pageData[index].pageId
+		if _, err := getIndexPage(*slideData, pageId); err != nil {
+			return err
+		}
+		// The page data written to the page before in this batch is not committed.
+		delta := int64(len(pageData[index].data)) - pendingSize(pageId, committed)
+		if delta > 0 {
+			if err := checkBytesLimit(*usedBytes + delta); err != nil {
+				return err
+			}
+		}
+		*usedBytes += delta
+		result.PageId = pageId
+		committed[pageId] = pageData[index]
+		return nil
+	case "delete_page":
+		pageId, err := resolvePageId(operation.PageId, index, operations, createdPageIds)
+		if err != nil {
+			return err
+		}
+		deleteIndex, err := getIndexPage(*slideData, pageId)
+		if err != nil {
+			return err
+		}
+		page := slideData.Pages[deleteIndex]
+		// The stored revisions of the page are freed when the trash is purged.
+		*usedBytes -= pendingSize(pageId, committed)
+		slideData.Pages = removePage(slideData.Pages, deleteIndex)
+		slideData.NumberOfPages--
+		*trashItems = append(*trashItems, TrashItem{
+			Type:    trashTypePage,
+			SlideId: slideData.Id,
+			Page:    &page,
+			Index:   deleteIndex,
+		})
+		// The page data written in this batch is not made current.
+		delete(committed, pageId)
+		return nil
+	case "swap":
+		return swapPages(slideData, operation.Origin, operation.Target)
+	case "move_page":
+		pageId, err := resolvePageId(operation.PageId, index, operations, createdPageIds)
+		if err != nil {
+			return err
+		}
+		return movePage(slideData, pageId, operation.Index)
+	case "set_order":
+		pageIds := []string{}
+		for _, pageId := range operation.PageIds {
+			resolvedId, err := resolvePageId(pageId, index, operations, createdPageIds)
+			if err != nil {
+				return err
+			}
+			pageIds = append(pageIds, resolvedId)
+		}
+		return orderPages(slideData, pageIds)
+	case "rename":
+		if len(operation.Title) == 0 {
+			return InvalidInput("title is required")
+		}
+		slideData.Title = operation.Title
+		return nil
+	}
+	return InvalidInput("unknown operation: %s", operation.Op)
+}
+
+// Returns the size of the page data to be committed to the page, or 0.
+func pendingSize(pageId string, committed map[string]*batchPageData) int64 {
+	if data, ok := committed[pageId]; ok {
+		return int64(len(data.data))
+	}
+	return 0
+}
+
+// Returns the page id. `$<n>` is the id of the page created by the n-th operation.
+func resolvePageId(pageId string, index int, operations []BatchOperation, createdPageIds map[int]string) (string, error) {
+	if len(pageId) == 0 {
+		return "", InvalidInput("page_id is required")
+	}
+	if !strings.HasPrefix(pageId, "$") {
+		return pageId, nil
+	}
+
+	target, err := strconv.Atoi(pageId[1:])
+	if err != nil || target < 0 || target >= index || operations[target].Op != "create_page" {
+		return "", InvalidInput("%s must refer to a previous create_page operation", pageId)
+	}
+	return createdPageIds[target], nil
+}
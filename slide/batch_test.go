@@ -0,0 +1,110 @@
+package slide
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBatchServesCommittedRevision(t *testing.T) {
+	cases := []struct {
+		name   string
+		atomic bool
+		// Operations after the set_page operations.
+		operations []BatchOperation
+		applied    bool
+	}{
+		{name: "applied", atomic: true, applied: true},
+		{name: "aborted", atomic: true, operations: []BatchOperation{{Op: "delete_page", PageId: "unknown"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, storageOp := newTestManager(t, "user")
+			slideId, pageIds := createTestSlide(t, s, 1)
+			pageId := pageIds[0]
+			if err := s.SetPage([]byte("v1"), slideId, pageId, storageOp); err != nil {
+				t.Fatal(err)
+			}
+
+			operations := append([]BatchOperation{
+				{Op: "set_page", PageId: pageId, Data: "v2"},
+				{Op: "set_page", PageId: pageId, Data: "v3"},
+				{Op: "create_page", PageType: "text"},
+				{Op: "set_page", PageId: "$2", Data: "new"},
+				{Op: "delete_page", PageId: "$2"},
+			}, c.operations...)
+			result, err := s.Batch(slideId, operations, c.atomic, storageOp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Applied != c.applied {
+				t.Fatalf("applied: got %v, want %v", result.Applied, c.applied)
+			}
+
+			want := "v1"
+			if c.applied {
+				want = "v3"
+			}
+			assertPage(t, s, storageOp, slideId, pageId, want)
+			pageInfo, err := s.StatPage(slideId, pageId, storageOp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			revisions, err := s.GetRevisions(slideId, pageId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			latest := revisions.Revisions[len(revisions.Revisions)-1]
+			if pageInfo.ETag != latest.Id {
+				t.Errorf("etag: got %s, want %s", pageInfo.ETag, latest.Id)
+			}
+
+			// Only the revisions in the history are stored.
+			paths, err := storageOp.List(strings.Join([]string{"revisions", "user", slideId}, "/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != len(revisions.Revisions) {
+				t.Errorf("stored revisions: got %v, want %d", paths, len(revisions.Revisions))
+			}
+		})
+	}
+}
+
+func TestBatchRejectsPageDataBeforeUpload(t *testing.T) {
+	defer func(bytes int64) {
+		maxStorageBytes = bytes
+	}(maxStorageBytes)
+	maxStorageBytes = 4
+
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 1)
+	if err := s.SetPage([]byte("1234"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+
+	operations := []BatchOperation{
+		{Op: "set_page", PageId: "unknown", Data: "a"},
+		{Op: "create_page", PageType: "text"},
+		{Op: "set_page", PageId: "$1", Data: "b"},
+	}
+	result, err := s.Batch(slideId, operations, false, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result.Results[0].Err, ErrPageNotFound) {
+		t.Errorf("unknown page: got %v, want ErrPageNotFound", result.Results[0].Err)
+	}
+	if !errors.Is(result.Results[2].Err, ErrQuotaExceeded) {
+		t.Errorf("over the quota: got %v, want ErrQuotaExceeded", result.Results[2].Err)
+	}
+
+	// The rejected page data is not uploaded.
+	paths, err := storageOp.List(strings.Join([]string{"revisions", "user", slideId}, "/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Errorf("stored revisions: got %v, want 1", paths)
+	}
+}
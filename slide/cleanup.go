@@ -0,0 +1,102 @@
+package slide
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+// Prefixes of the page data that have been deleted from the state but may remain in the storage.
+type cleanupList struct {
+	Prefixes []string `json:"prefixes"`
+}
+
+// Returns the state key of the cleanup list.
+func (s *SlideManager) cleanupKey() string {
+	return strings.Join([]string{s.userId, "cleanup"}, "|")
+}
+
+// Read the cleanup list with its etag.
+func (s *SlideManager) readCleanup() (*cleanupList, string, error) {
+	getData, err := s.state.Get(s.cleanupKey())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var list cleanupList
+	if utf8.RuneCount(getData.Value) != 0 {
+		if err := json.Unmarshal(getData.Value, &list); err != nil {
+			return nil, "", err
+		}
+	}
+	return &list, getData.Etag, nil
+}
+
+// Returns the transaction operation that adds prefixes to the cleanup list.
+// It is committed with the state deletion so that the page data is always deleted later.
+func (s *SlideManager) cleanupOperation(prefixes ...string) (state.Operation, error) {
+	list, etag, err := s.readCleanup()
+	if err != nil {
+		return state.Operation{}, err
+	}
+	list.Prefixes = append(list.Prefixes, prefixes...)
+
+	return upsertOperation(s.cleanupKey(), list, etag)
+}
+
+// Delete the page data in the cleanup list.
+// A prefix that fails to be deleted remains in the list and is retried at the next call.
+//
+// Arguments:
+// - storageOp: storage op instance
+func (s *SlideManager) Cleanup(storageOp storage.BlobStore) error {
+	list, _, err := s.readCleanup()
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range list.Prefixes {
+		if err := storageOp.Delete(prefix); err != nil {
+			return err
+		}
+		if err := s.removeCleanup(prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove the deleted prefix from the cleanup list.
+func (s *SlideManager) removeCleanup(prefix string) error {
+	return retryOnConflict(func() error {
+		list, etag, err := s.readCleanup()
+		if err != nil {
+			return err
+		}
+
+		prefixes := []string{}
+		for _, element := range list.Prefixes {
+			if element != prefix {
+				prefixes = append(prefixes, element)
+			}
+		}
+		if len(prefixes) == 0 {
+			return s.state.DeleteWithETag(s.cleanupKey(), etag)
+		}
+		list.Prefixes = prefixes
+
+		return s.writeDocument(s.cleanupKey(), list, etag)
+	})
+}
+
+// Delete the page data after the state deletion has been committed.
+// The state is already consistent, so the failure is only logged and retried later.
+func (s *SlideManager) cleanupAfterCommit(storageOp storage.BlobStore) {
+	if err := s.Cleanup(storageOp); err != nil {
+		log.Printf("failed to delete page data of %s: %v", s.userId, err)
+	}
+}
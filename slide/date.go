@@ -2,6 +2,8 @@ package slide
 
 import "time"
 
+var jst = time.FixedZone("Asia/Tokyo", 9*60*60)
+
 type DateOp struct {
 	nowUTC time.Time
 	nowJST time.Time
@@ -10,7 +12,6 @@ type DateOp struct {
 func newDateOp() *DateOp {
 	now := time.Now()
 	nowUTC := now.UTC()
-	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
 	nowJST := nowUTC.In(jst)
 
 	return &DateOp{
@@ -22,3 +23,18 @@ func newDateOp() *DateOp {
 func (d *DateOp) getDateJST() string {
 	return d.nowJST.Format("20060102150405")
 }
+
+// Parse the date formatted by getDateJST.
+func parseDateJST(date string) (time.Time, error) {
+	return time.ParseInLocation("20060102150405", date, jst)
+}
+
+// Returns the change date as time.
+// Returns zero time if it is invalid.
+func (c *SlideContent) ChangeTime() time.Time {
+	changeTime, err := parseDateJST(c.ChangeDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return changeTime
+}
@@ -0,0 +1,213 @@
+package slide
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+// Number of attempts when the document is changed by another request.
+const maxAttempts = 5
+
+// Returns the state key of the slide details.
+func (s *SlideManager) detailsKey(slideId string) string {
+	return strings.Join([]string{s.userId, slideId}, "|")
+}
+
+// Read the slides infomation of user with its etag.
+// If it does not exist, returns empty SlideConfig and empty etag.
+func (s *SlideManager) readInfo() (*SlideConfig, string, error) {
+	getData, err := s.state.Get(s.userId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if utf8.RuneCount(getData.Value) != 0 {
+		var slideConfig SlideConfig
+
+		if err := json.Unmarshal(getData.Value, &slideConfig); err != nil {
+			return nil, "", err
+		}
+		return &slideConfig, getData.Etag, nil
+	}
+	// Not exist
+	return &SlideConfig{
+		NumberOfSlides: 0,
+		Slides:         []SlideContent{},
+	}, "", nil
+}
+
+// Read the slide details with its etag.
+// If it does not exist, returns nil.
+func (s *SlideManager) readDetails(slideId string) (*SlideData, string, error) {
+	getData, err := s.state.Get(s.detailsKey(slideId))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if utf8.RuneCount(getData.Value) == 0 {
+		return nil, "", nil
+	}
+
+	var slideData SlideData
+
+	if err := json.Unmarshal(getData.Value, &slideData); err != nil {
+		return nil, "", err
+	}
+	return &slideData, getData.Etag, nil
+}
+
+// Read the slide details with its etag.
+// If it does not exist, it is created from the slides infomation.
+func (s *SlideManager) loadDetails(slideId string) (*SlideData, string, error) {
+	slideData, etag, err := s.readDetails(slideId)
+	if err != nil || slideData != nil {
+		return slideData, etag, err
+	}
+
+	// Not exist
+	// Create Slide Data
+	slidesConfig, _, err := s.readInfo()
+	if err != nil {
+		return nil, "", err
+	}
+	targetIndex, err := getIndexSlideConfig(*slidesConfig, slideId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	newSlideInfo := &SlideData{
+		NumberOfPages: 0,
+		Pages:         []PageData{},
+		SlideContent:  slidesConfig.Slides[targetIndex],
+	}
+	// If another request has created it first, read that one.
+	if err := s.writeDocument(s.detailsKey(slideId), newSlideInfo, ""); err != nil && !errors.Is(err, state.ErrETagMismatch) {
+		return nil, "", err
+	}
+
+	slideData, etag, err = s.readDetails(slideId)
+	if err != nil {
+		return nil, "", err
+	}
+	if slideData == nil {
+		// Deleted by another request.
+		return nil, "", state.ErrETagMismatch
+	}
+	return slideData, etag, nil
+}
+
+// Returns the transaction operations that delete the slide details, the revisions of its pages, its grants, its links and its search index.
+// The slide remains in the lists of the shared users, but it is hidden because the owner does not have it.
+func (s *SlideManager) deleteDetailsOperations(slideId string) ([]state.Operation, error) {
+	slideData, _, err := s.readDetails(slideId)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []state.Operation{
+		{
+			Type: state.OperationDelete,
+			Key:  s.detailsKey(slideId),
+		},
+		{
+			Type: state.OperationDelete,
+			Key:  s.aclKey(slideId),
+		},
+		{
+			Type: state.OperationDelete,
+			Key:  s.linksKey(slideId),
+		},
+		{
+			Type: state.OperationDelete,
+			Key:  s.searchIndexKey(slideId),
+		},
+	}
+	links, _, err := s.readLinks(slideId)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links.Links {
+		operations = append(operations, state.Operation{
+			Type: state.OperationDelete,
+			Key:  linkKey(link.Id),
+		})
+	}
+	if slideData != nil {
+		for _, page := range slideData.Pages {
+			operations = append(operations, state.Operation{
+				Type: state.OperationDelete,
+				Key:  s.revisionsKey(slideId, page.PageId),
+			})
+		}
+	}
+	return operations, nil
+}
+
+// Write the document only if it has not been changed since it was read with etag.
+func (s *SlideManager) writeDocument(key string, document interface{}, etag string) error {
+	body, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return s.state.SetWithETag(key, body, etag)
+}
+
+// Returns the transaction operation that writes the document only if it has not been changed since it was read with etag.
+func upsertOperation(key string, document interface{}, etag string) (state.Operation, error) {
+	body, err := json.Marshal(document)
+	if err != nil {
+		return state.Operation{}, err
+	}
+	return state.Operation{
+		Type:  state.OperationUpsert,
+		Key:   key,
+		Value: body,
+		Etag:  etag,
+	}, nil
+}
+
+// Write the slide details and `title` and `change_date` of the slide in the user's slides infomation in one transaction.
+// The additional operations are applied in the same transaction.
+func (s *SlideManager) commitDetails(slideData *SlideData, etag string, operations ...state.Operation) error {
+	slideConfig, infoEtag, err := s.readInfo()
+	if err != nil {
+		return err
+	}
+	targetIndex, err := getIndexSlideConfig(*slideConfig, slideData.Id)
+	if err != nil {
+		return err
+	}
+	slideConfig.Slides[targetIndex].Title = slideData.Title
+	slideConfig.Slides[targetIndex].ChangeDate = slideData.ChangeDate
+
+	detailsOperation, err := upsertOperation(s.detailsKey(slideData.Id), slideData, etag)
+	if err != nil {
+		return err
+	}
+	infoOperation, err := upsertOperation(s.userId, slideConfig, infoEtag)
+	if err != nil {
+		return err
+	}
+
+	return s.state.Transaction(append([]state.Operation{detailsOperation, infoOperation}, operations...))
+}
+
+// Run the read-modify-write fn again while another request changes the documents.
+// Returns ErrConflict when all attempts conflict.
+func retryOnConflict(fn func() error) error {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := fn()
+		if !errors.Is(err, state.ErrETagMismatch) {
+			return err
+		}
+		// Shift the timing from the other request.
+		time.Sleep(time.Duration(rand.Intn(20*attempt)) * time.Millisecond)
+	}
+	return ErrConflict
+}
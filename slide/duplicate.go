@@ -0,0 +1,159 @@
+package slide
+
+import (
+	"log"
+	"strings"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+	"github.com/hello-slide/slide-manager/utils"
+)
+
+// Duplicate slide with all pages.
+// The pages get new ids and their page data is copied in the storage.
+// The slide shared with the user is duplicated into the user's slides.
+//
+// Arguments:
+// - slideId: Id of the source slide.
+// - newTitle: title of the new slide.
+// - storageOp: storage op instance
+//
+// Return:
+// - id string: Id of the new slide.
+func (s *SlideManager) Duplicate(slideId string, newTitle string, storageOp storage.BlobStore) (string, error) {
+	source, err := s.access(slideId, RoleViewer)
+	if err != nil {
+		return "", err
+	}
+	slideDetails, err := source.GetSlideDetails(slideId)
+	if err != nil {
+		return "", err
+	}
+	// The search index of the source slide is not changed by the transaction, so read it first.
+	sourceIndex, _, err := source.readSearchIndex(slideId)
+	if err != nil {
+		return "", err
+	}
+
+	newSlideId, err := utils.CreateId(newTitle)
+	if err != nil {
+		return "", err
+	}
+
+	dstDirs := []string{
+		"pages",
+		s.userId,
+		newSlideId,
+	}
+
+	// Check the quotas before copying the page data.
+	slideConfig, err := s.GetInfo()
+	if err != nil {
+		return "", err
+	}
+	if err := checkSlidesQuota(slideConfig); err != nil {
+		return "", err
+	}
+	var copiedBytes int64
+	for _, page := range slideDetails.Pages {
+		copiedBytes += page.Size
+	}
+	if err := s.checkBytesQuota(copiedBytes); err != nil {
+		return "", err
+	}
+
+	pages := []PageData{}
+	// Page ids of the new slide by the page ids of the source slide.
+	pageIds := map[string]string{}
+	for _, page := range slideDetails.Pages {
+		newPageId, err := utils.CreateId(strings.Join([]string{newSlideId, page.PageId}, "|"))
+		if err != nil {
+			return "", err
+		}
+
+		srcDirs, srcFileName := source.pageLocation(slideId, page)
+		isExist, err := storageOp.FileExist(srcDirs, srcFileName)
+		if err != nil {
+			return "", err
+		}
+		if isExist {
+			if err := storageOp.Copy(srcDirs, srcFileName, dstDirs, newPageId); err != nil {
+				s.deleteCopied(storageOp, dstDirs)
+				return "", err
+			}
+		}
+
+		pageIds[page.PageId] = newPageId
+		pages = append(pages, PageData{
+			PageId: newPageId,
+			Type:   page.Type,
+			Size:   page.Size,
+		})
+	}
+
+	dateOp := newDateOp()
+	slideContent := SlideContent{
+		Title:      newTitle,
+		Id:         newSlideId,
+		CreateDate: dateOp.getDateJST(),
+		ChangeDate: dateOp.getDateJST(),
+	}
+	newSlideData := &SlideData{
+		NumberOfPages: len(pages),
+		Pages:         pages,
+		SlideContent:  slideContent,
+	}
+
+	err = retryOnConflict(func() error {
+		slideConfig, etag, err := s.readInfo()
+		if err != nil {
+			return err
+		}
+
+		if err := checkSlidesQuota(slideConfig); err != nil {
+			return err
+		}
+		slideConfig.NumberOfSlides++
+		slideConfig.Slides = append(slideConfig.Slides, slideContent)
+
+		infoOperation, err := upsertOperation(s.userId, slideConfig, etag)
+		if err != nil {
+			return err
+		}
+		detailsOperation, err := upsertOperation(s.detailsKey(newSlideId), newSlideData, "")
+		if err != nil {
+			return err
+		}
+
+		searchOperation, err := s.searchIndexOperation(newSlideId, func(index *searchIndex) {
+			index.set(newSlideId, tokenize(newTitle))
+			for pageId, newPageId := range pageIds {
+				tokens := sourceIndex.Documents[pageDocumentKey(slideId, pageId)]
+				index.set(pageDocumentKey(newSlideId, newPageId), append([]string{}, tokens...))
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		usageOperation, err := s.addBytesOperation(copiedBytes)
+		if err != nil {
+			return err
+		}
+
+		return s.state.Transaction([]state.Operation{infoOperation, detailsOperation, searchOperation, usageOperation})
+	})
+	if err != nil {
+		s.deleteCopied(storageOp, dstDirs)
+		return "", err
+	}
+
+	return newSlideId, nil
+}
+
+// Delete the page data copied for the slide that has not been created.
+func (s *SlideManager) deleteCopied(storageOp storage.BlobStore, dirs []string) {
+	if err := storageOp.Delete(strings.Join(dirs, "/")); err != nil {
+		log.Printf("failed to delete copied page data of %s: %v", s.userId, err)
+	}
+}
@@ -1,5 +1,47 @@
 package slide
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 var slideInfoState string = os.Getenv("SLIDE_CONFIG")
+
+// Number of revisions kept for each page.
+var maxRevisions int = getEnvInt("MAX_REVISIONS", 20)
+
+// Max bytes of the page data.
+var maxPageSize int64 = int64(getEnvInt("MAX_PAGE_SIZE", 8<<20))
+
+// Max number of slides of each user. Unlimited if 0.
+var maxSlides int = getEnvInt("MAX_SLIDES", 1000)
+
+// Max number of pages of each slide. Unlimited if 0.
+var maxPagesPerSlide int = getEnvInt("MAX_PAGES_PER_SLIDE", 1000)
+
+// Max total bytes of the page data of each user. Unlimited if 0.
+var maxStorageBytes int64 = int64(getEnvInt("MAX_STORAGE_BYTES", 1<<30))
+
+// Period to keep the slides and pages in the trash.
+var trashRetention time.Duration = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+
+// Returns the environment variable as int.
+// If it is not set or invalid, returns defaultValue.
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// Returns the environment variable as time.Duration such as `720h`.
+// If it is not set or invalid, returns defaultValue.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
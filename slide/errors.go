@@ -0,0 +1,62 @@
+package slide
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of the slide operations.
+// Use errors.Is to check them because they may be wrapped by *Error.
+var (
+	ErrSlideNotFound     = errors.New("the specified slide does not exist")
+	ErrPageNotFound      = errors.New("the specified page does not exist")
+	ErrRevisionNotFound  = errors.New("the specified revision does not exist")
+	ErrTrashItemNotFound = errors.New("the specified item does not exist in the trash")
+	ErrIndexOutOfRange   = errors.New("the specified index is out of range")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+	ErrPageTooLarge      = errors.New("the page data is too large")
+	ErrGrantNotFound     = errors.New("the slide is not shared with the specified user")
+	ErrLinkNotFound      = errors.New("the share link does not exist or has expired")
+	ErrLinkPassword      = errors.New("the password of the share link is wrong")
+
+	// The slide is shared with the user, but the role does not allow the operation.
+	ErrForbidden = errors.New("the user does not have permission for the operation on the slide")
+
+	// The page has been changed since the version specified by If-Match.
+	ErrPreconditionFailed = errors.New("the page has been changed by another request")
+
+	// The document has been changed by another request many times and the update has been given up.
+	ErrConflict = errors.New("the slide was changed by another request, please try again")
+)
+
+// Error with a detailed message.
+// It wraps one of the errors of the slide operations.
+type Error struct {
+	Err     error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Returns ErrInvalidInput with the message.
+func InvalidInput(format string, a ...interface{}) error {
+	return &Error{
+		Err:     ErrInvalidInput,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// Returns ErrQuotaExceeded with the message.
+func quotaExceeded(format string, a ...interface{}) error {
+	return &Error{
+		Err:     ErrQuotaExceeded,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
@@ -0,0 +1,327 @@
+package slide
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Max number of the active links of a slide.
+const maxLinks = 20
+
+// Iterations of PBKDF2 for the link password.
+const passwordIterations = 100000
+
+// Link document found by the token.
+// Only the hash of the token is stored, so the token can not be read from the state store.
+type linkDocument struct {
+	OwnerId    string `json:"owner_id"`
+	SlideId    string `json:"slide_id"`
+	ExpireDate string `json:"expire_date,omitempty"`
+	// `pbkdf2-sha256$<iterations>$<salt>$<hash>`. Empty if the link has no password.
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+// Returns the state key of the link.
+func linkKey(linkId string) string {
+	return strings.Join([]string{"share-link", linkId}, "|")
+}
+
+// Returns the state key of the links of the slide.
+func (s *SlideManager) linksKey(slideId string) string {
+	return strings.Join([]string{s.userId, slideId, "links"}, "|")
+}
+
+// Returns the link id of the token.
+// The id is the hash of the token, so it can be used as a key without exposing the token.
+func LinkId(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Read the links of the slide.
+// If it does not exist, returns empty ShareLinks and empty etag.
+func (s *SlideManager) readLinks(slideId string) (*ShareLinks, string, error) {
+	links := &ShareLinks{
+		Links: []ShareLink{},
+	}
+	etag, err := s.readJSON(s.linksKey(slideId), links)
+	return links, etag, err
+}
+
+// Returns true if the link of expireDate has expired.
+func linkExpired(expireDate string, now time.Time) bool {
+	if len(expireDate) == 0 {
+		return false
+	}
+	expireTime, err := parseDateJST(expireDate)
+	return err != nil || !now.Before(expireTime)
+}
+
+// Create a public read-only link of the slide.
+// Only the owner can create it.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - expiresIn: period until the link expires. It does not expire if zero.
+// - password: password required to open the link. Not required if empty.
+//
+// Return:
+// - link *CreatedShareLink: the link with its token. The token can not be read again.
+func (s *SlideManager) CreateLink(slideId string, expiresIn time.Duration, password string) (*CreatedShareLink, error) {
+	if expiresIn < 0 {
+		return nil, InvalidInput("expires_in must not be negative")
+	}
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	dateOp := newDateOp()
+	link := ShareLink{
+		Id:          LinkId(token),
+		SlideId:     slideId,
+		CreateDate:  dateOp.getDateJST(),
+		HasPassword: len(password) != 0,
+	}
+	if expiresIn > 0 {
+		link.ExpireDate = dateOp.nowJST.Add(expiresIn).Format("20060102150405")
+	}
+	document := linkDocument{
+		OwnerId:    s.userId,
+		SlideId:    slideId,
+		ExpireDate: link.ExpireDate,
+	}
+	if len(password) != 0 {
+		passwordHash, err := hashPassword(password)
+		if err != nil {
+			return nil, err
+		}
+		document.PasswordHash = passwordHash
+	}
+
+	err := retryOnConflict(func() error {
+		links, etag, err := s.readLinks(slideId)
+		if err != nil {
+			return err
+		}
+
+		// Delete the expired links meanwhile.
+		operations := []state.Operation{}
+		activeLinks := []ShareLink{}
+		for _, activeLink := range links.Links {
+			if linkExpired(activeLink.ExpireDate, dateOp.nowUTC) {
+				operations = append(operations, state.Operation{
+					Type: state.OperationDelete,
+					Key:  linkKey(activeLink.Id),
+				})
+				continue
+			}
+			activeLinks = append(activeLinks, activeLink)
+		}
+		if len(activeLinks) >= maxLinks {
+			return InvalidInput("the slide can have at most %d links", maxLinks)
+		}
+		links.Links = append(activeLinks, link)
+
+		linksOperation, err := upsertOperation(s.linksKey(slideId), links, etag)
+		if err != nil {
+			return err
+		}
+		linkOperation, err := upsertOperation(linkKey(link.Id), document, "")
+		if err != nil {
+			return err
+		}
+		// The state store deletes the expired link document. The expiration is also checked when it is read.
+		linkOperation.TTL = expiresIn
+		return s.state.Transaction(append(operations, linksOperation, linkOperation))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatedShareLink{
+		Token:     token,
+		ShareLink: link,
+	}, nil
+}
+
+// Get the active links of the slide.
+// Only the owner can see them.
+//
+// Arguments:
+// - slideId: Id of slide.
+func (s *SlideManager) GetLinks(slideId string) (*ShareLinks, error) {
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	links, _, err := s.readLinks(slideId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	activeLinks := []ShareLink{}
+	for _, link := range links.Links {
+		if !linkExpired(link.ExpireDate, now) {
+			activeLinks = append(activeLinks, link)
+		}
+	}
+	links.Links = activeLinks
+	return links, nil
+}
+
+// Revoke the link of the slide.
+// Only the owner can revoke it.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - linkId: Id of the link.
+func (s *SlideManager) RevokeLink(slideId string, linkId string) error {
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return err
+	}
+
+	return retryOnConflict(func() error {
+		links, etag, err := s.readLinks(slideId)
+		if err != nil {
+			return err
+		}
+
+		activeLinks := []ShareLink{}
+		for _, link := range links.Links {
+			if link.Id != linkId {
+				activeLinks = append(activeLinks, link)
+			}
+		}
+		if len(activeLinks) == len(links.Links) {
+			return ErrLinkNotFound
+		}
+		links.Links = activeLinks
+
+		linksOperation, err := upsertOperation(s.linksKey(slideId), links, etag)
+		if err != nil {
+			return err
+		}
+		return s.state.Transaction([]state.Operation{
+			linksOperation,
+			{
+				Type: state.OperationDelete,
+				Key:  linkKey(linkId),
+			},
+		})
+	})
+}
+
+// Read the slide by the token of the link.
+// The page data is not read, and each page is opened by OpenLinkPage.
+// The user of s is not used, so it can be called without the session.
+//
+// Arguments:
+// - token: token of the link.
+// - password: password of the link. Ignored if the link has no password.
+func (s *SlideManager) ReadLink(token string, password string) (*LinkedSlide, error) {
+	document := &linkDocument{}
+	if _, err := s.readJSON(linkKey(LinkId(token)), document); err != nil {
+		return nil, err
+	}
+	if len(document.OwnerId) == 0 {
+		return nil, ErrLinkNotFound
+	}
+	if linkExpired(document.ExpireDate, time.Now()) {
+		return nil, ErrLinkNotFound
+	}
+	if len(document.PasswordHash) != 0 && !verifyPassword(password, document.PasswordHash) {
+		return nil, ErrLinkPassword
+	}
+
+	// The slide in the trash can not be read by the link.
+	slideData, err := s.withUser(document.OwnerId).GetSlideDetails(document.SlideId)
+	if errors.Is(err, ErrSlideNotFound) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &LinkedSlide{
+		OwnerId: document.OwnerId,
+		Slide:   slideData,
+	}, nil
+}
+
+// Open the page data of the slide by the token of the link as OpenPage.
+// The user of s is not used, so it can be called without the session.
+//
+// Arguments:
+// - token: token of the link.
+// - password: password of the link. Ignored if the link has no password.
+// - pageId: Id of page.
+// - storageOp: storage op instance
+//
+// Return:
+// - reader io.ReadCloser: page data. Close it after reading.
+// - pageInfo *PageInfo: size and version of the page data.
+func (s *SlideManager) OpenLinkPage(token string, password string, pageId string, storageOp storage.BlobStore) (io.ReadCloser, *PageInfo, error) {
+	linkedSlide, err := s.ReadLink(token, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, pageInfo, err := s.withUser(linkedSlide.OwnerId).OpenPage(linkedSlide.Slide.Id, pageId, storageOp)
+	// The slide may be moved to the trash meanwhile.
+	if errors.Is(err, ErrSlideNotFound) {
+		return nil, nil, ErrLinkNotFound
+	}
+	return reader, pageInfo, err
+}
+
+// Returns the hash of the password with a random salt.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash := pbkdf2.Key([]byte(password), salt, passwordIterations, sha256.Size, sha256.New)
+	return strings.Join([]string{
+		"pbkdf2-sha256",
+		strconv.Itoa(passwordIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	}, "$"), nil
+}
+
+// Returns true if the password matches the hash made by hashPassword.
+func verifyPassword(password string, passwordHash string) bool {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(hash) == 0 {
+		return false
+	}
+	return hmac.Equal(pbkdf2.Key([]byte(password), salt, iterations, len(hash), sha256.New), hash)
+}
@@ -0,0 +1,148 @@
+package slide
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+func TestReadLink(t *testing.T) {
+	cases := []struct {
+		name     string
+		password string
+		input    string
+		err      error
+	}{
+		{name: "no password", input: "ignored"},
+		{name: "password", password: "secret", input: "secret"},
+		{name: "wrong password", password: "secret", input: "wrong", err: ErrLinkPassword},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, storageOp := newTestManager(t, "owner")
+			slideId, pageIds := createTestSlide(t, s, 2)
+			if err := s.SetPage([]byte("shared data"), slideId, pageIds[1], storageOp); err != nil {
+				t.Fatal(err)
+			}
+			link, err := s.CreateLink(slideId, 0, c.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			anonymous := s.withUser("")
+
+			linkedSlide, err := anonymous.ReadLink(link.Token, c.input)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("got %v, want %v", err, c.err)
+			}
+			if c.err != nil {
+				return
+			}
+			if linkedSlide.Slide.Id != slideId || len(linkedSlide.Slide.Pages) != 2 {
+				t.Errorf("got %+v", linkedSlide.Slide)
+			}
+
+			reader, pageInfo, err := anonymous.OpenLinkPage(link.Token, c.input, pageIds[1], storageOp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "shared data" || pageInfo.Size != int64(len(data)) {
+				t.Errorf("got %q (%d bytes)", data, pageInfo.Size)
+			}
+
+			// The page of another slide can not be opened by the link.
+			otherId, otherPageIds := createTestSlide(t, s, 1)
+			if _, _, err := anonymous.OpenLinkPage(link.Token, c.input, otherPageIds[0], storageOp); err == nil {
+				t.Errorf("the page of %s is opened by the link of %s", otherId, slideId)
+			}
+
+			if err := s.RevokeLink(slideId, link.Id); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := anonymous.ReadLink(link.Token, c.input); !errors.Is(err, ErrLinkNotFound) {
+				t.Errorf("revoked link: got %v", err)
+			}
+		})
+	}
+}
+
+// State store that records the operations of the transactions.
+type recordingState struct {
+	state.StateStore
+	operations []state.Operation
+}
+
+func (s *recordingState) Transaction(operations []state.Operation) error {
+	s.operations = append(s.operations, operations...)
+	return s.StateStore.Transaction(operations)
+}
+
+func TestCreateLinkTTL(t *testing.T) {
+	cases := []struct {
+		name      string
+		expiresIn time.Duration
+	}{
+		{name: "expires", expiresIn: time.Hour},
+		{name: "no expiration", expiresIn: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stateStore := &recordingState{StateStore: state.NewMemoryState()}
+			s := NewSlideManagerWithState(context.Background(), stateStore, "owner")
+			slideId, _ := createTestSlide(t, s, 0)
+
+			link, err := s.CreateLink(slideId, c.expiresIn, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			written := false
+			for _, operation := range stateStore.operations {
+				if operation.Key != linkKey(link.Id) {
+					continue
+				}
+				written = true
+				if operation.TTL != c.expiresIn {
+					t.Errorf("ttl: got %v, want %v", operation.TTL, c.expiresIn)
+				}
+			}
+			if !written {
+				t.Error("the link document is not written")
+			}
+			if _, err := s.withUser("").ReadLink(link.Token, ""); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	passwordHash, err := hashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		password     string
+		passwordHash string
+		want         bool
+	}{
+		{password: "secret", passwordHash: passwordHash, want: true},
+		{password: "wrong", passwordHash: passwordHash, want: false},
+		// The first 32 bytes of the PBKDF2-HMAC-SHA256 test vector of RFC 7914.
+		{password: "passwd", passwordHash: "pbkdf2-sha256$1$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLw", want: true},
+		{password: "secret", passwordHash: "pbkdf2-sha256$0$c2FsdA$VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLw", want: false},
+		{password: "secret", passwordHash: "sha256$secret", want: false},
+	}
+	for _, c := range cases {
+		if got := verifyPassword(c.password, c.passwordHash); got != c.want {
+			t.Errorf("%s, %s: got %v, want %v", c.password, c.passwordHash, got, c.want)
+		}
+	}
+}
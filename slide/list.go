@@ -0,0 +1,168 @@
+package slide
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// List slides of user with pagination, sorting and filtering.
+// The slides shared with the user follow the user's own slides, and they have OwnerId and Role.
+//
+// Arguments:
+// - options: options of listing.
+func (s *SlideManager) ListSlides(options ListOptions) (*SlideList, error) {
+	if err := validateListOptions(options); err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(options.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	slideConfig, err := s.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	sharedSlides, err := s.getSharedSlides()
+	if err != nil {
+		return nil, err
+	}
+
+	slides := []SlideContent{}
+	for _, slide := range append(slideConfig.Slides, sharedSlides...) {
+		if inDateRange(slide.CreateDate, options.CreatedAfter, options.CreatedBefore) &&
+			inDateRange(slide.ChangeDate, options.ChangedAfter, options.ChangedBefore) {
+			slides = append(slides, slide)
+		}
+	}
+	sortSlides(slides, options.SortBy, options.Descending)
+
+	slideList := &SlideList{
+		Slides:     []SlideContent{},
+		TotalCount: len(slides),
+	}
+	if offset >= len(slides) {
+		return slideList, nil
+	}
+
+	end := len(slides)
+	if options.Limit > 0 && offset+options.Limit < end {
+		end = offset + options.Limit
+		slideList.NextCursor = encodeCursor(end)
+	}
+	slideList.Slides = slides[offset:end]
+
+	return slideList, nil
+}
+
+// Get all slides of user in the format of the slides infomation.
+// The slides shared with the user follow the user's own slides as in ListSlides,
+// and NumberOfSlides counts only the own slides as in the stored slides infomation.
+func (s *SlideManager) GetAllSlides() (*SlideConfig, error) {
+	slideList, err := s.ListSlides(ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	numberOfSlides := 0
+	for _, slideContent := range slideList.Slides {
+		if len(slideContent.OwnerId) == 0 {
+			numberOfSlides++
+		}
+	}
+	return &SlideConfig{
+		NumberOfSlides: numberOfSlides,
+		Slides:         slideList.Slides,
+	}, nil
+}
+
+func validateListOptions(options ListOptions) error {
+	switch options.SortBy {
+	case "", "title", "create_date", "change_date":
+	default:
+		return InvalidInput("sort must be title, create_date or change_date")
+	}
+	if options.Limit < 0 {
+		return InvalidInput("limit must not be negative")
+	}
+
+	dates := []string{
+		options.CreatedAfter,
+		options.CreatedBefore,
+		options.ChangedAfter,
+		options.ChangedBefore,
+	}
+	for _, date := range dates {
+		if len(date) == 0 {
+			continue
+		}
+		if _, err := parseDateJST(date); err != nil {
+			return InvalidInput("date must be formatted as 20060102150405")
+		}
+	}
+	return nil
+}
+
+// Check if after <= date <= before.
+// The empty range is not limited.
+func inDateRange(date string, after string, before string) bool {
+	// The dates have the same length, so they can be compared as string.
+	if len(after) != 0 && date < after {
+		return false
+	}
+	if len(before) != 0 && date > before {
+		return false
+	}
+	return true
+}
+
+// Sort slides by the key.
+// The empty key keeps the order of creation.
+func sortSlides(slides []SlideContent, sortBy string, descending bool) {
+	key := func(slide SlideContent) string {
+		switch sortBy {
+		case "title":
+			return strings.ToLower(slide.Title)
+		case "create_date":
+			return slide.CreateDate
+		case "change_date":
+			return slide.ChangeDate
+		}
+		return ""
+	}
+
+	sort.SliceStable(slides, func(i, j int) bool {
+		if descending {
+			return key(slides[i]) > key(slides[j])
+		}
+		return key(slides[i]) < key(slides[j])
+	})
+
+	if len(sortBy) == 0 && descending {
+		for i, j := 0, len(slides)-1; i < j; i, j = i+1, j-1 {
+			slides[i], slides[j] = slides[j], slides[i]
+		}
+	}
+}
+
+// The cursor is the offset of the next page.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if len(cursor) == 0 {
+		return 0, nil
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, InvalidInput("invalid cursor")
+	}
+	offset, err := strconv.Atoi(string(value))
+	if err != nil || offset < 0 {
+		return 0, InvalidInput("invalid cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,35 @@
+package slide
+
+import (
+	"testing"
+)
+
+func TestGetAllSlidesIncludesSharedSlides(t *testing.T) {
+	owner, _ := newTestManager(t, "owner")
+	user := owner.withUser("user")
+	sharedId, _ := createTestSlide(t, owner, 0)
+	if _, err := owner.Create("not shared"); err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.Share(sharedId, "user", RoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	ownId, _ := createTestSlide(t, user, 0)
+
+	slideConfig, err := user.GetAllSlides()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the own slide is counted.
+	if slideConfig.NumberOfSlides != 1 || len(slideConfig.Slides) != 2 {
+		t.Fatalf("got %+v", slideConfig)
+	}
+	own := slideConfig.Slides[0]
+	if own.Id != ownId || len(own.OwnerId) != 0 || len(own.Role) != 0 {
+		t.Errorf("own slide: got %+v", own)
+	}
+	shared := slideConfig.Slides[1]
+	if shared.Id != sharedId || shared.OwnerId != "owner" || shared.Role != RoleEditor {
+		t.Errorf("shared slide: got %+v", shared)
+	}
+}
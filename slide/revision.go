@@ -0,0 +1,211 @@
+package slide
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+// Returns the state key of the page revisions.
+func (s *SlideManager) revisionsKey(slideId string, pageId string) string {
+	return strings.Join([]string{s.userId, slideId, pageId, "revisions"}, "|")
+}
+
+// Returns the storage directories of the page revisions.
+func (s *SlideManager) revisionDirs(slideId string, pageId string) []string {
+	return []string{
+		"revisions",
+		s.userId,
+		slideId,
+		pageId,
+	}
+}
+
+// Read the page revisions with its etag.
+func (s *SlideManager) readRevisions(slideId string, pageId string) (*PageRevisions, string, error) {
+	getData, err := s.state.Get(s.revisionsKey(slideId, pageId))
+	if err != nil {
+		return nil, "", err
+	}
+
+	revisions := PageRevisions{
+		Revisions: []Revision{},
+	}
+	if utf8.RuneCount(getData.Value) != 0 {
+		if err := json.Unmarshal(getData.Value, &revisions); err != nil {
+			return nil, "", err
+		}
+	}
+	return &revisions, getData.Etag, nil
+}
+
+// Add the revision and remove the oldest ones over maxRevisions.
+// Returns the removed revisions.
+func addRevision(revisions *PageRevisions, revisionId string, size int) []Revision {
+	dateOp := newDateOp()
+	revisions.LatestNumber++
+	revisions.Revisions = append(revisions.Revisions, Revision{
+		Number: revisions.LatestNumber,
+		Id:     revisionId,
+		Date:   dateOp.getDateJST(),
+		Size:   size,
+	})
+
+	// The latest revision is the current page data, so it is always kept.
+	keep := maxRevisions
+	if keep < 1 {
+		keep = 1
+	}
+	if len(revisions.Revisions) <= keep {
+		return []Revision{}
+	}
+	pruned := revisions.Revisions[:len(revisions.Revisions)-keep]
+	revisions.Revisions = revisions.Revisions[len(revisions.Revisions)-keep:]
+	return pruned
+}
+
+// Returns the transaction operation that adds the revision, the paths of the pruned revisions and their bytes.
+// The pruned paths must be added to the cleanup list in the same transaction.
+func (s *SlideManager) addRevisionOperation(slideId string, pageId string, revisionId string, size int) (state.Operation, []string, int64, error) {
+	revisions, etag, err := s.readRevisions(slideId, pageId)
+	if err != nil {
+		return state.Operation{}, nil, 0, err
+	}
+
+	prunePaths := []string{}
+	var prunedBytes int64
+	dirs := s.revisionDirs(slideId, pageId)
+	for _, revision := range addRevision(revisions, revisionId, size) {
+		prunePaths = append(prunePaths, strings.Join(append(dirs, revision.Id), "/"))
+		prunedBytes += int64(revision.Size)
+	}
+
+	revisionsOperation, err := upsertOperation(s.revisionsKey(slideId, pageId), revisions, etag)
+	if err != nil {
+		return state.Operation{}, nil, 0, err
+	}
+	return revisionsOperation, prunePaths, prunedBytes, nil
+}
+
+// Returns the bytes that writing a revision of size to the page adds to the usage.
+// The pruned revisions and the page data before the revisions are freed.
+func (s *SlideManager) revisionBytesDelta(slideId string, page PageData, size int64) (int64, error) {
+	revisions, _, err := s.readRevisions(slideId, page.PageId)
+	if err != nil {
+		return 0, err
+	}
+
+	delta := size
+	for _, revision := range addRevision(revisions, "", int(size)) {
+		delta -= int64(revision.Size)
+	}
+	if len(page.RevisionId) == 0 {
+		delta -= page.Size
+	}
+	return delta, nil
+}
+
+// Returns the stored bytes of the page: its revisions and the page data before the revisions.
+func (s *SlideManager) storedPageBytes(slideId string, page PageData) (int64, error) {
+	revisions, _, err := s.readRevisions(slideId, page.PageId)
+	if err != nil {
+		return 0, err
+	}
+
+	var bytes int64
+	for _, revision := range revisions.Revisions {
+		bytes += int64(revision.Size)
+	}
+	if len(page.RevisionId) == 0 {
+		bytes += page.Size
+	}
+	return bytes, nil
+}
+
+// Get revisions of page.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - pageId: Id of page.
+func (s *SlideManager) GetRevisions(slideId string, pageId string) (*PageRevisions, error) {
+	s, err := s.access(slideId, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	slideDetails, err := s.GetSlideDetails(slideId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getIndexPage(*slideDetails, pageId); err != nil {
+		return nil, err
+	}
+
+	revisions, _, err := s.readRevisions(slideId, pageId)
+	return revisions, err
+}
+
+// Get page data of the revision.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - pageId: Id of page.
+// - number: revision number.
+// - storageOp: storage op instance
+func (s *SlideManager) GetRevision(slideId string, pageId string, number int, storageOp storage.BlobStore) ([]byte, error) {
+	s, err := s.access(slideId, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.findRevision(slideId, pageId, number)
+	if err != nil {
+		return nil, err
+	}
+	return storageOp.ReadFile(s.revisionDirs(slideId, pageId), revision.Id)
+}
+
+// Returns the revision of the number.
+func (s *SlideManager) findRevision(slideId string, pageId string, number int) (*Revision, error) {
+	revisions, err := s.GetRevisions(slideId, pageId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions.Revisions {
+		if revision.Number == number {
+			return &revision, nil
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+// Restore page data of the revision.
+// The restored data is saved as a new revision.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - pageId: Id of page.
+// - number: revision number.
+// - storageOp: storage op instance
+func (s *SlideManager) RestoreRevision(slideId string, pageId string, number int, storageOp storage.BlobStore) error {
+	s, err := s.access(slideId, RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	revision, err := s.findRevision(slideId, pageId, number)
+	if err != nil {
+		return err
+	}
+	// The revision is streamed to the new one without buffering it entirely.
+	reader, fileInfo, err := storageOp.OpenFile(s.revisionDirs(slideId, pageId), revision.Id)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return s.SetPageFrom(reader, fileInfo.Size, slideId, pageId, "", storageOp)
+}
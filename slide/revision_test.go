@@ -0,0 +1,169 @@
+package slide
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+// Returns the slide manager on the in-memory state store and the local storage in a temporary directory.
+func newTestManager(t *testing.T, userId string) (*SlideManager, storage.BlobStore) {
+	t.Helper()
+	storageOp, err := storage.NewLocalStorageOp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSlideManagerWithState(context.Background(), state.NewMemoryState(), userId), storageOp
+}
+
+// Create a slide with the pages and returns the ids.
+func createTestSlide(t *testing.T, s *SlideManager, numberOfPages int) (string, []string) {
+	t.Helper()
+	slideId, err := s.Create("slide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageIds := []string{}
+	for index := 0; index < numberOfPages; index++ {
+		page, err := s.CreatePage(slideId, "text")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pageIds = append(pageIds, page.PageId)
+	}
+	return slideId, pageIds
+}
+
+func assertPage(t *testing.T, s *SlideManager, storageOp storage.BlobStore, slideId string, pageId string, want string) {
+	t.Helper()
+	data, err := s.GetPage(slideId, pageId, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("page data: got %q, want %q", data, want)
+	}
+}
+
+func TestSetPageServesCommittedRevision(t *testing.T) {
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 1)
+	pageId := pageIds[0]
+
+	if err := s.SetPage([]byte("v1"), slideId, pageId, storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertPage(t, s, storageOp, slideId, pageId, "v1")
+	pageInfo, err := s.StatPage(slideId, pageId, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetPage([]byte("v2"), slideId, pageId, storageOp); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stale ETag fails and leaves neither the page data nor a revision.
+	err = s.SetPageFrom(strings.NewReader("v3"), 2, slideId, pageId, pageInfo.ETag, storageOp)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("got %v, want ErrPreconditionFailed", err)
+	}
+	assertPage(t, s, storageOp, slideId, pageId, "v2")
+	revisions, err := s.GetRevisions(slideId, pageId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions.Revisions) != 2 {
+		t.Errorf("revisions: got %d, want 2", len(revisions.Revisions))
+	}
+	names, err := storageOp.List("revisions/user/" + slideId + "/" + pageId + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("revision files: got %v, want 2 files", names)
+	}
+
+	pageInfo, err = s.StatPage(slideId, pageId, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pageInfo.ETag != revisions.Revisions[1].Id {
+		t.Errorf("ETag: got %q, want the latest revision %q", pageInfo.ETag, revisions.Revisions[1].Id)
+	}
+	if err := s.SetPageFrom(strings.NewReader("v3"), 2, slideId, pageId, pageInfo.ETag, storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertPage(t, s, storageOp, slideId, pageId, "v3")
+}
+
+func TestPruneRevisionsKeepsCurrentPage(t *testing.T) {
+	defer func(value int) { maxRevisions = value }(maxRevisions)
+	maxRevisions = 2
+
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 1)
+	pageId := pageIds[0]
+
+	for _, data := range []string{"v1", "v2", "v3"} {
+		if err := s.SetPage([]byte(data), slideId, pageId, storageOp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertPage(t, s, storageOp, slideId, pageId, "v3")
+
+	revisions, err := s.GetRevisions(slideId, pageId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions.Revisions) != 2 || revisions.Revisions[0].Number != 2 || revisions.Revisions[1].Number != 3 {
+		t.Errorf("revisions: got %+v, want numbers 2 and 3", revisions.Revisions)
+	}
+	names, err := storageOp.List("revisions/user/" + slideId + "/" + pageId + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("revision files: got %v, want 2 files", names)
+	}
+}
+
+// Blob store that does not read a file entirely.
+type streamOnlyStore struct {
+	storage.BlobStore
+}
+
+func (s streamOnlyStore) ReadFile(dirs []string, fileName string) ([]byte, error) {
+	return nil, errors.New("the file is read entirely")
+}
+
+func TestRestoreRevisionStreamsData(t *testing.T) {
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 1)
+	pageId := pageIds[0]
+	for _, data := range []string{"v1", "v2"} {
+		if err := s.SetPage([]byte(data), slideId, pageId, storageOp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.RestoreRevision(slideId, pageId, 1, streamOnlyStore{storageOp}); err != nil {
+		t.Fatal(err)
+	}
+	assertPage(t, s, storageOp, slideId, pageId, "v1")
+	revisions, err := s.GetRevisions(slideId, pageId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := revisions.Revisions[len(revisions.Revisions)-1]; latest.Number != 3 || latest.Size != 2 {
+		t.Errorf("latest revision: got %+v", latest)
+	}
+
+	if err := s.RestoreRevision(slideId, pageId, 9, storageOp); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("unknown revision: got %v", err)
+	}
+}
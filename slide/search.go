@@ -0,0 +1,416 @@
+package slide
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Max bytes of the page data to be indexed.
+const maxIndexSize int = 64 << 10
+
+// Number of hits if the limit is not specified.
+const defaultSearchLimit int = 20
+
+// Number of runes around the matched word in the snippet.
+const snippetRadius int = 40
+
+// Inverted index of the title and pages of a slide.
+// The index is split by slide, so it is as small as the slide details and it is updated only with its slide.
+// The document key is `<slide id>` for the title and `<slide id>/<page id>` for the page.
+type searchIndex struct {
+	// Document keys of each token.
+	Tokens map[string][]string `json:"tokens"`
+	// Tokens of each document.
+	Documents map[string][]string `json:"documents"`
+}
+
+// Returns the document key of the page.
+func pageDocumentKey(slideId string, pageId string) string {
+	return strings.Join([]string{slideId, pageId}, "/")
+}
+
+// Set the tokens of the document.
+func (index *searchIndex) set(key string, tokens []string) {
+	index.remove(key)
+	if len(tokens) == 0 {
+		return
+	}
+
+	index.Documents[key] = tokens
+	for _, token := range tokens {
+		index.Tokens[token] = append(index.Tokens[token], key)
+	}
+}
+
+// Remove the document.
+func (index *searchIndex) remove(key string) {
+	for _, token := range index.Documents[key] {
+		keys := []string{}
+		for _, element := range index.Tokens[token] {
+			if element != key {
+				keys = append(keys, element)
+			}
+		}
+		if len(keys) == 0 {
+			delete(index.Tokens, token)
+		} else {
+			index.Tokens[token] = keys
+		}
+	}
+	delete(index.Documents, key)
+}
+
+// Returns the document keys containing all tokens.
+func (index *searchIndex) search(tokens []string) map[string]bool {
+	if len(tokens) == 0 {
+		return map[string]bool{}
+	}
+
+	matches := map[string]bool{}
+	for _, key := range index.Tokens[tokens[0]] {
+		matches[key] = true
+	}
+	for _, token := range tokens[1:] {
+		keys := map[string]bool{}
+		for _, key := range index.Tokens[token] {
+			if matches[key] {
+				keys[key] = true
+			}
+		}
+		matches = keys
+	}
+	return matches
+}
+
+// Returns the state key of the search index of the slide.
+func (s *SlideManager) searchIndexKey(slideId string) string {
+	return strings.Join([]string{s.userId, slideId, "search"}, "|")
+}
+
+// Read the search index of the slide with its etag.
+func (s *SlideManager) readSearchIndex(slideId string) (*searchIndex, string, error) {
+	getData, err := s.state.Get(s.searchIndexKey(slideId))
+	if err != nil {
+		return nil, "", err
+	}
+
+	index, err := decodeSearchIndex(getData.Value)
+	if err != nil {
+		return nil, "", err
+	}
+	return index, getData.Etag, nil
+}
+
+// Read the search indexes of the slides at once.
+func (s *SlideManager) readSearchIndexes(slideIds []string) ([]*searchIndex, error) {
+	keys := make([]string, 0, len(slideIds))
+	for _, slideId := range slideIds {
+		keys = append(keys, s.searchIndexKey(slideId))
+	}
+	items, err := s.state.BulkGet(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]*searchIndex, 0, len(items))
+	for _, item := range items {
+		index, err := decodeSearchIndex(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// Decode the stored search index. An empty value is an empty index.
+func decodeSearchIndex(value []byte) (*searchIndex, error) {
+	index := searchIndex{}
+	if utf8.RuneCount(value) != 0 {
+		if err := json.Unmarshal(value, &index); err != nil {
+			return nil, err
+		}
+	}
+	if index.Tokens == nil {
+		index.Tokens = map[string][]string{}
+	}
+	if index.Documents == nil {
+		index.Documents = map[string][]string{}
+	}
+	return &index, nil
+}
+
+// Returns the transaction operation that updates the search index of the slide.
+// It is committed with the change of the slide so that the index is always consistent.
+func (s *SlideManager) searchIndexOperation(slideId string, update func(index *searchIndex)) (state.Operation, error) {
+	index, etag, err := s.readSearchIndex(slideId)
+	if err != nil {
+		return state.Operation{}, err
+	}
+	update(index)
+
+	return upsertOperation(s.searchIndexKey(slideId), index, etag)
+}
+
+// Returns the tokens of the page data to index.
+func pageTokens(data []byte) []string {
+	if len(data) > maxIndexSize {
+		data = data[:maxIndexSize]
+	}
+	return tokenize(string(data))
+}
+
+// Read the page data up to maxIndexSize, which is the indexed part of the page.
+func readIndexedData(storageOp storage.BlobStore, dirs []string, fileName string) ([]byte, error) {
+	reader, _, err := storageOp.OpenFile(dirs, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, int64(maxIndexSize)))
+}
+
+// Search slides and pages of user.
+// The hits contain all words of the query, and the titles come before the pages.
+//
+// Arguments:
+// - query: search words.
+// - limit: max number of hits. 20 if zero.
+// - storageOp: storage op instance
+func (s *SlideManager) Search(query string, limit int, storageOp storage.BlobStore) (*SearchResult, error) {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil, InvalidInput("the query has no words to search")
+	}
+	if limit < 0 {
+		return nil, InvalidInput("limit must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	// The slides in the trash are not in the slides infomation.
+	slideConfig, err := s.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+	slideIds := make([]string, 0, len(slideConfig.Slides))
+	for _, slideContent := range slideConfig.Slides {
+		slideIds = append(slideIds, slideContent.Id)
+	}
+	indexes, err := s.readSearchIndexes(slideIds)
+	if err != nil {
+		return nil, err
+	}
+	matches := map[string]bool{}
+	for _, index := range indexes {
+		for key := range index.search(tokens) {
+			matches[key] = true
+		}
+	}
+
+	words := queryWords(query)
+	result := &SearchResult{
+		Hits: []SearchHit{},
+	}
+
+	// Titles.
+	for _, slideContent := range slideConfig.Slides {
+		if len(result.Hits) >= limit {
+			return result, nil
+		}
+		if matches[slideContent.Id] {
+			snippet, highlights := makeSnippet(slideContent.Title, words)
+			result.Hits = append(result.Hits, SearchHit{
+				SlideId:    slideContent.Id,
+				Title:      slideContent.Title,
+				Snippet:    snippet,
+				Highlights: highlights,
+			})
+		}
+	}
+
+	// Pages, in the order of the slides and the pages.
+	for _, slideContent := range slideConfig.Slides {
+		pageIds := []string{}
+		for key := range matches {
+			if strings.HasPrefix(key, slideContent.Id+"/") {
+				pageIds = append(pageIds, strings.TrimPrefix(key, slideContent.Id+"/"))
+			}
+		}
+		if len(pageIds) == 0 {
+			continue
+		}
+
+		slideDetails, err := s.GetSlideDetails(slideContent.Id)
+		if err != nil {
+			return nil, err
+		}
+		// The pages in the trash are kept in the index until they are purged.
+		pageIndexes := map[string]int{}
+		existPageIds := []string{}
+		for _, pageId := range pageIds {
+			if pageIndex, err := getIndexPage(*slideDetails, pageId); err == nil {
+				pageIndexes[pageId] = pageIndex
+				existPageIds = append(existPageIds, pageId)
+			}
+		}
+		pageIds = existPageIds
+		sort.Slice(pageIds, func(i, j int) bool {
+			return pageIndexes[pageIds[i]] < pageIndexes[pageIds[j]]
+		})
+
+		for _, pageId := range pageIds {
+			if len(result.Hits) >= limit {
+				return result, nil
+			}
+			dirs, fileName := s.pageLocation(slideContent.Id, slideDetails.Pages[pageIndexes[pageId]])
+			data, err := readIndexedData(storageOp, dirs, fileName)
+			if err != nil {
+				return nil, err
+			}
+			snippet, highlights := makeSnippet(string(data), words)
+			result.Hits = append(result.Hits, SearchHit{
+				SlideId:    slideContent.Id,
+				Title:      slideContent.Title,
+				PageId:     pageId,
+				Snippet:    snippet,
+				Highlights: highlights,
+			})
+		}
+	}
+	return result, nil
+}
+
+// Returns true if the rune is written without spaces between words,
+// such as Japanese and Chinese.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// Normalize the text for indexing.
+// The full-width alphabets and the half-width katakana are unified by NFKC.
+func normalize(text string) string {
+	return strings.ToLower(norm.NFKC.String(text))
+}
+
+// Split the text into the unique tokens.
+// The words of CJK are split into unigrams and bigrams because they are written without spaces,
+// so that a query of one character matches the words containing it.
+// The other words are split by the non-letters.
+func tokenize(text string) []string {
+	tokens := []string{}
+	seen := map[string]bool{}
+	add := func(token string) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	for _, word := range splitWords(normalize(text)) {
+		runes := []rune(word)
+		if !isCJK(runes[0]) {
+			add(word)
+			continue
+		}
+		for index := range runes {
+			add(string(runes[index]))
+			if index+1 < len(runes) {
+				add(string(runes[index : index+2]))
+			}
+		}
+	}
+	return tokens
+}
+
+// Split the text into words.
+// The sequence of CJK and the sequence of the other letters and numbers are words.
+func splitWords(text string) []string {
+	words := []string{}
+	var word []rune
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != 'ー' {
+			if len(word) != 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if len(word) != 0 && isCJK(word[len(word)-1]) != isCJK(r) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) != 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// Returns the normalized words of the query to highlight.
+func queryWords(query string) []string {
+	return splitWords(normalize(query))
+}
+
+// Returns the part of the text around the first matched word and the ranges of the words in it.
+// The text is normalized rune by rune to find the words at the offsets of the original text.
+func makeSnippet(text string, words []string) (string, []Highlight) {
+	runes := []rune(text)
+	normalized := []rune{}
+	// Offset in runes of the original text for each normalized rune.
+	offsets := []int{}
+	for index, r := range runes {
+		for _, normalizedRune := range normalize(string(r)) {
+			normalized = append(normalized, normalizedRune)
+			offsets = append(offsets, index)
+		}
+	}
+	offsets = append(offsets, len(runes))
+
+	matches := []Highlight{}
+	for _, word := range words {
+		wordRunes := []rune(word)
+		for index := 0; index+len(wordRunes) <= len(normalized); index++ {
+			if string(normalized[index:index+len(wordRunes)]) == word {
+				matches = append(matches, Highlight{
+					Start: offsets[index],
+					End:   offsets[index+len(wordRunes)],
+				})
+				index += len(wordRunes) - 1
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	start := 0
+	if len(matches) != 0 && matches[0].Start > snippetRadius {
+		start = matches[0].Start - snippetRadius
+	}
+	end := start + snippetRadius*2
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	highlights := []Highlight{}
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			highlights = append(highlights, Highlight{
+				Start: match.Start - start,
+				End:   match.End - start,
+			})
+		}
+	}
+	return string(runes[start:end]), highlights
+}
@@ -0,0 +1,121 @@
+package slide
+
+import (
+	"testing"
+
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+func TestSearchPage(t *testing.T) {
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 3)
+
+	if err := s.SetPage([]byte("hello world"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPage([]byte("こんにちは世界"), slideId, pageIds[1], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPage([]byte("東京タワー"), slideId, pageIds[2], storageOp); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query   string
+		pageId  string
+		snippet string
+	}{
+		{query: "WORLD", pageId: pageIds[0], snippet: "hello world"},
+		{query: "世界", pageId: pageIds[1], snippet: "こんにちは世界"},
+		{query: "東", pageId: pageIds[2], snippet: "東京タワー"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			// The snippets are read from the stream of the page data.
+			result, err := s.Search(c.query, 0, streamOnlyStore{storageOp})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Hits) != 1 {
+				t.Fatalf("got %d hits, want 1", len(result.Hits))
+			}
+			hit := result.Hits[0]
+			if hit.SlideId != slideId || hit.PageId != c.pageId || hit.Snippet != c.snippet {
+				t.Errorf("got %+v", hit)
+			}
+		})
+	}
+}
+
+func TestSearchIndexPerSlide(t *testing.T) {
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 2)
+	if err := s.Rename(slideId, "quarterly report"); err != nil {
+		t.Fatal(err)
+	}
+	for _, pageId := range pageIds {
+		if err := s.SetPage([]byte("sales figures"), slideId, pageId, storageOp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copyId, err := s.Duplicate(slideId, "copy", storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each slide has its own index.
+	for _, id := range []string{slideId, copyId} {
+		index, _, err := s.readSearchIndex(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(index.Documents) != 3 {
+			t.Errorf("%s: got %d documents, want 3", id, len(index.Documents))
+		}
+	}
+	assertHits(t, s, storageOp, "quarterly", 1)
+	assertHits(t, s, storageOp, "sales", 4)
+
+	// The purged page is removed from the index.
+	if err := s.DeletePage(slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PurgeTrash(storageOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertHits(t, s, storageOp, "sales", 3)
+	index, _, err := s.readSearchIndex(slideId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := index.Documents[pageDocumentKey(slideId, pageIds[0])]; ok {
+		t.Error("the purged page is in the index")
+	}
+
+	// The index of the purged slide is deleted.
+	if err := s.Delete(copyId, storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PurgeTrash(storageOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	item, err := s.state.Get(s.searchIndexKey(copyId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(item.Value) != 0 {
+		t.Errorf("the index of the purged slide remains: %s", item.Value)
+	}
+}
+
+// Check the number of hits of the query.
+func assertHits(t *testing.T, s *SlideManager, storageOp storage.BlobStore, query string, want int) {
+	t.Helper()
+	result, err := s.Search(query, 0, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hits) != want {
+		t.Errorf("%s: got %d hits, want %d", query, len(result.Hits), want)
+	}
+}
@@ -0,0 +1,314 @@
+package slide
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+// Role of the user on a slide.
+type Role string
+
+const (
+	// Can read the slide and its pages.
+	RoleViewer Role = "viewer"
+	// Can also change the pages and the title.
+	RoleEditor Role = "editor"
+	// Can also delete the slide and share it.
+	RoleOwner Role = "owner"
+)
+
+// Max number of the users that a slide is shared with.
+const maxGrants = 100
+
+// Returns true if the role has the permissions of required.
+func (r Role) allows(required Role) bool {
+	ranks := map[Role]int{
+		RoleViewer: 1,
+		RoleEditor: 2,
+		RoleOwner:  3,
+	}
+	return ranks[r] >= ranks[required]
+}
+
+// Returns the state key of the users that the slide is shared with.
+func (s *SlideManager) aclKey(slideId string) string {
+	return strings.Join([]string{s.userId, slideId, "acl"}, "|")
+}
+
+// Returns the state key of the slides shared with the user.
+func sharedKey(userId string) string {
+	return strings.Join([]string{userId, "shared"}, "|")
+}
+
+// Read the users that the slide is shared with.
+// If it does not exist, returns empty SlideACL and empty etag.
+func (s *SlideManager) readACL(slideId string) (*SlideACL, string, error) {
+	acl := &SlideACL{
+		Grants: []Grant{},
+	}
+	etag, err := s.readJSON(s.aclKey(slideId), acl)
+	return acl, etag, err
+}
+
+// Read the slides shared with the user.
+// If it does not exist, returns empty SharedSlides and empty etag.
+func (s *SlideManager) readShared(userId string) (*SharedSlides, string, error) {
+	shared := &SharedSlides{
+		Slides: []SharedSlide{},
+	}
+	etag, err := s.readJSON(sharedKey(userId), shared)
+	return shared, etag, err
+}
+
+// Read the json document into v and returns its etag.
+// v is not changed if the document does not exist.
+func (s *SlideManager) readJSON(key string, v interface{}) (string, error) {
+	getData, err := s.state.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if utf8.RuneCount(getData.Value) == 0 {
+		return "", nil
+	}
+	if err := json.Unmarshal(getData.Value, v); err != nil {
+		return "", err
+	}
+	return getData.Etag, nil
+}
+
+// Returns the slide manager of the slide owner if the user has the role on the slide.
+// For the user's own slide, returns s.
+// The role is checked against the grants in the owner's data,
+// and returns ErrSlideNotFound if the slide is not shared with the user or it is in the trash.
+// The methods of the slide replace s with the returned one to operate on the owner's data.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - role: role required for the operation.
+func (s *SlideManager) access(slideId string, role Role) (*SlideManager, error) {
+	slideConfig, _, err := s.readInfo()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getIndexSlideConfig(*slideConfig, slideId); err == nil {
+		return s, nil
+	}
+
+	shared, _, err := s.readShared(s.userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, sharedSlide := range shared.Slides {
+		if sharedSlide.SlideId != slideId {
+			continue
+		}
+
+		owner := s.withUser(sharedSlide.OwnerId)
+		acl, _, err := owner.readACL(slideId)
+		if err != nil {
+			return nil, err
+		}
+		index := acl.indexOf(s.userId)
+		if index < 0 {
+			return nil, ErrSlideNotFound
+		}
+		if !acl.Grants[index].Role.allows(role) {
+			return nil, ErrForbidden
+		}
+
+		ownerConfig, _, err := owner.readInfo()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := getIndexSlideConfig(*ownerConfig, slideId); err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}
+	return nil, ErrSlideNotFound
+}
+
+// Returns the slide manager of another user on the same state store.
+func (s *SlideManager) withUser(userId string) *SlideManager {
+	return &SlideManager{
+		ctx:    s.ctx,
+		userId: userId,
+		state:  s.state,
+	}
+}
+
+// Share the slide with the user, or change the role of the user.
+// Only the owner can share the slide.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - userId: user to share with.
+// - role: `viewer` or `editor`.
+func (s *SlideManager) Share(slideId string, userId string, role Role) error {
+	if role != RoleViewer && role != RoleEditor {
+		return InvalidInput("role must be viewer or editor")
+	}
+	if len(userId) == 0 {
+		return InvalidInput("user_id is required")
+	}
+	if userId == s.userId {
+		return InvalidInput("the slide can not be shared with the owner")
+	}
+	// The grants have at most the editor role, so only the owner passes.
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return err
+	}
+
+	return retryOnConflict(func() error {
+		acl, aclEtag, err := s.readACL(slideId)
+		if err != nil {
+			return err
+		}
+		grant := Grant{
+			UserId:    userId,
+			Role:      role,
+			GrantDate: newDateOp().getDateJST(),
+		}
+		if index := acl.indexOf(userId); index >= 0 {
+			acl.Grants[index] = grant
+		} else {
+			if len(acl.Grants) >= maxGrants {
+				return InvalidInput("the slide can be shared with at most %d users", maxGrants)
+			}
+			acl.Grants = append(acl.Grants, grant)
+		}
+
+		shared, sharedEtag, err := s.readShared(userId)
+		if err != nil {
+			return err
+		}
+		shared.remove(s.userId, slideId)
+		shared.Slides = append(shared.Slides, SharedSlide{
+			OwnerId: s.userId,
+			SlideId: slideId,
+			Role:    role,
+		})
+
+		aclOperation, err := upsertOperation(s.aclKey(slideId), acl, aclEtag)
+		if err != nil {
+			return err
+		}
+		sharedOperation, err := upsertOperation(sharedKey(userId), shared, sharedEtag)
+		if err != nil {
+			return err
+		}
+		return s.state.Transaction([]state.Operation{aclOperation, sharedOperation})
+	})
+}
+
+// Stop sharing the slide with the user.
+// Only the owner can revoke the grants.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - userId: user to stop sharing with.
+func (s *SlideManager) Unshare(slideId string, userId string) error {
+	// The grants have at most the editor role, so only the owner passes.
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return err
+	}
+
+	return retryOnConflict(func() error {
+		acl, aclEtag, err := s.readACL(slideId)
+		if err != nil {
+			return err
+		}
+		index := acl.indexOf(userId)
+		if index < 0 {
+			return ErrGrantNotFound
+		}
+		acl.Grants = append(acl.Grants[:index], acl.Grants[index+1:]...)
+
+		shared, sharedEtag, err := s.readShared(userId)
+		if err != nil {
+			return err
+		}
+		shared.remove(s.userId, slideId)
+
+		aclOperation, err := upsertOperation(s.aclKey(slideId), acl, aclEtag)
+		if err != nil {
+			return err
+		}
+		sharedOperation, err := upsertOperation(sharedKey(userId), shared, sharedEtag)
+		if err != nil {
+			return err
+		}
+		return s.state.Transaction([]state.Operation{aclOperation, sharedOperation})
+	})
+}
+
+// Get the users that the slide is shared with.
+// Only the owner can see them.
+//
+// Arguments:
+// - slideId: Id of slide.
+func (s *SlideManager) GetGrants(slideId string) (*SlideACL, error) {
+	if _, err := s.access(slideId, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	acl, _, err := s.readACL(slideId)
+	return acl, err
+}
+
+// Returns the slides shared with the user.
+// OwnerId and Role are set to them.
+// The slides that the owner has deleted are not returned.
+func (s *SlideManager) getSharedSlides() ([]SlideContent, error) {
+	shared, _, err := s.readShared(s.userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerConfigs := map[string]*SlideConfig{}
+	slides := []SlideContent{}
+	for _, sharedSlide := range shared.Slides {
+		slideConfig, ok := ownerConfigs[sharedSlide.OwnerId]
+		if !ok {
+			slideConfig, _, err = s.withUser(sharedSlide.OwnerId).readInfo()
+			if err != nil {
+				return nil, err
+			}
+			ownerConfigs[sharedSlide.OwnerId] = slideConfig
+		}
+
+		index, err := getIndexSlideConfig(*slideConfig, sharedSlide.SlideId)
+		if err != nil {
+			continue
+		}
+		slide := slideConfig.Slides[index]
+		slide.OwnerId = sharedSlide.OwnerId
+		slide.Role = sharedSlide.Role
+		slides = append(slides, slide)
+	}
+	return slides, nil
+}
+
+// Returns the index of the grant of the user, or -1.
+func (acl *SlideACL) indexOf(userId string) int {
+	for index, grant := range acl.Grants {
+		if grant.UserId == userId {
+			return index
+		}
+	}
+	return -1
+}
+
+// Remove the slide of the owner.
+func (shared *SharedSlides) remove(ownerId string, slideId string) {
+	slides := []SharedSlide{}
+	for _, sharedSlide := range shared.Slides {
+		if sharedSlide.OwnerId != ownerId || sharedSlide.SlideId != slideId {
+			slides = append(slides, sharedSlide)
+		}
+	}
+	shared.Slides = slides
+}
@@ -1,11 +1,12 @@
 package slide
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
+	"io/ioutil"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/dapr/go-sdk/client"
 	"github.com/hello-slide/slide-manager/state"
@@ -16,15 +17,26 @@ import (
 type SlideManager struct {
 	ctx    context.Context
 	userId string
-	client *client.Client
+	state  state.StateStore
 }
 
 func NewSlideManager(ctx context.Context, daprClient *client.Client, userId string) *SlideManager {
+	slideManager := &SlideManager{
+		ctx:    ctx,
+		userId: userId,
+	}
+	slideManager.state = state.NewState(daprClient, &slideManager.ctx, slideInfoState)
 
+	return slideManager
+}
+
+// Create slide manager with any state store.
+// It does not need the Dapr sidecar when using the in-memory or file state store.
+func NewSlideManagerWithState(ctx context.Context, stateStore state.StateStore, userId string) *SlideManager {
 	return &SlideManager{
 		ctx:    ctx,
 		userId: userId,
-		client: daprClient,
+		state:  stateStore,
 	}
 }
 
@@ -50,21 +62,32 @@ func (s *SlideManager) Create(title string) (string, error) {
 		ChangeDate: dateOp.getDateJST(),
 	}
 
-	slideConfig, err := s.GetInfo()
-	if err != nil {
-		return "", err
-	}
+	err = retryOnConflict(func() error {
+		slideConfig, etag, err := s.readInfo()
+		if err != nil {
+			return err
+		}
+		if err := checkSlidesQuota(slideConfig); err != nil {
+			return err
+		}
 
-	slideConfig.NumberOfSlides++
-	slideConfig.Slides = append(slideConfig.Slides, slideContent)
+		slideConfig.NumberOfSlides++
+		slideConfig.Slides = append(slideConfig.Slides, slideContent)
 
-	body, err := json.Marshal(slideConfig)
-	if err != nil {
-		return "", err
-	}
+		infoOperation, err := upsertOperation(s.userId, slideConfig, etag)
+		if err != nil {
+			return err
+		}
+		searchOperation, err := s.searchIndexOperation(slideId, func(index *searchIndex) {
+			index.set(slideId, tokenize(title))
+		})
+		if err != nil {
+			return err
+		}
 
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	if err := slideInfo.Set(s.userId, body); err != nil {
+		return s.state.Transaction([]state.Operation{infoOperation, searchOperation})
+	})
+	if err != nil {
 		return "", err
 	}
 
@@ -77,9 +100,7 @@ func (s *SlideManager) Create(title string) (string, error) {
 // - slideId: Id of slide.
 // - pageType: page type.
 func (s *SlideManager) CreatePage(slideId string, pageType string) (*PageData, error) {
-	id := strings.Join([]string{s.userId, slideId}, "|")
-
-	slideDetails, err := s.GetSlideDetails(slideId)
+	s, err := s.access(slideId, RoleEditor)
 	if err != nil {
 		return nil, err
 	}
@@ -94,23 +115,24 @@ func (s *SlideManager) CreatePage(slideId string, pageType string) (*PageData, e
 		Type:   pageType,
 	}
 
-	slideDetails.NumberOfPages++
-	slideDetails.Pages = append(slideDetails.Pages, *pageDate)
-
-	dateOp := newDateOp()
-	slideDetails.ChangeDate = dateOp.getDateJST()
+	err = retryOnConflict(func() error {
+		slideDetails, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
+		if err := checkPagesQuota(slideDetails); err != nil {
+			return err
+		}
 
-	body, err := json.Marshal(slideDetails)
-	if err != nil {
-		return nil, err
-	}
+		slideDetails.NumberOfPages++
+		slideDetails.Pages = append(slideDetails.Pages, *pageDate)
 
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	if err := slideInfo.Set(id, body); err != nil {
-		return nil, err
-	}
+		dateOp := newDateOp()
+		slideDetails.ChangeDate = dateOp.getDateJST()
 
-	if err := s.changedDateUpdate(true, false, slideId); err != nil {
+		return s.commitDetails(slideDetails, etag)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -124,43 +146,169 @@ func (s *SlideManager) CreatePage(slideId string, pageType string) (*PageData, e
 // - slideId: Id of slide.
 // - pageId: Id of page.
 // - storageOp: storage op instance
-func (s *SlideManager) SetPage(data []byte, slideId string, pageId string, storageOp storage.StorageOp) error {
-	dirs := []string{
-		"pages",
-		s.userId,
-		slideId,
+func (s *SlideManager) SetPage(data []byte, slideId string, pageId string, storageOp storage.BlobStore) error {
+	return s.SetPageFrom(bytes.NewReader(data), int64(len(data)), slideId, pageId, "", storageOp)
+}
+
+// Write page data read from body.
+// The data is streamed to the storage without buffering it entirely.
+//
+// Arguments:
+// - body: page data.
+// - size: size of the page data, such as Content-Length. -1 if unknown.
+// - slideId: Id of slide.
+// - pageId: Id of page.
+// - ifMatch: ETag of the page that the data is based on, or empty for no condition. Returns ErrPreconditionFailed if it is not the current one.
+// - storageOp: storage op instance
+func (s *SlideManager) SetPageFrom(body io.Reader, size int64, slideId string, pageId string, ifMatch string, storageOp storage.BlobStore) error {
+	s, err := s.access(slideId, RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if size > maxPageSize {
+		return pageTooLarge()
 	}
-	if err := storageOp.WriteFile(dirs, pageId, data); err != nil {
+
+	// Do not leave the page data of a page that does not exist.
+	slideDetails, err := s.GetSlideDetails(slideId)
+	if err != nil {
 		return err
 	}
-	if err := s.changedDateUpdate(true, true, slideId); err != nil {
+	pageIndex, err := getIndexPage(*slideDetails, pageId)
+	if err != nil {
 		return err
 	}
-	return nil
-}
 
-// Get Slides infomation of user.
-func (s *SlideManager) GetInfo() (*SlideConfig, error) {
+	// Fail before reading the data if the page has already been changed.
+	if len(ifMatch) != 0 {
+		pageInfo, err := s.pageInfo(slideId, slideDetails.Pages[pageIndex], storageOp)
+		if err != nil {
+			return err
+		}
+		if pageInfo.ETag != ifMatch {
+			return ErrPreconditionFailed
+		}
+	}
 
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	getData, err := slideInfo.Get(s.userId)
+	// The stored bytes are counted for the owner of the slide.
+	if size >= 0 {
+		delta, err := s.revisionBytesDelta(slideId, slideDetails.Pages[pageIndex], size)
+		if err != nil {
+			return err
+		}
+		if err := s.checkBytesQuota(delta); err != nil {
+			return err
+		}
+	}
+
+	// Save a revision with the data, and keep the head of it for the search index.
+	// The revision becomes the current page data when it is committed.
+	revisionId, err := utils.CreateId(pageId)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	revisionPath := strings.Join(append(s.revisionDirs(slideId, pageId), revisionId), "/")
+	head := &headBuffer{limit: maxIndexSize}
+	reader := io.TeeReader(&sizeLimitReader{reader: body, limit: maxPageSize}, head)
+	written, err := storageOp.WriteStream(s.revisionDirs(slideId, pageId), revisionId, reader)
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		storageOp.Delete(revisionPath)
+		return InvalidInput("the page data is %d bytes, but the size is %d bytes", written, size)
+	}
+	if size < 0 {
+		delta, err := s.revisionBytesDelta(slideId, slideDetails.Pages[pageIndex], written)
+		if err == nil {
+			err = s.checkBytesQuota(delta)
+		}
+		if err != nil {
+			storageOp.Delete(revisionPath)
+			return err
+		}
+	}
+
+	isPruned := false
+	err = retryOnConflict(func() error {
+		slideDetails, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
+		pageIndex, err := getIndexPage(*slideDetails, pageId)
+		if err != nil {
+			return err
+		}
+		page := &slideDetails.Pages[pageIndex]
+
+		// Check again because another request may have written it while reading the data.
+		if len(ifMatch) != 0 {
+			pageInfo, err := s.pageInfo(slideId, *page, storageOp)
+			if err != nil {
+				return err
+			}
+			if pageInfo.ETag != ifMatch {
+				return ErrPreconditionFailed
+			}
+		}
+
+		dateOp := newDateOp()
+		slideDetails.ChangeDate = dateOp.getDateJST()
+
+		revisionsOperation, prunePaths, prunedBytes, err := s.addRevisionOperation(slideId, pageId, revisionId, int(written))
+		if err != nil {
+			return err
+		}
+		operations := []state.Operation{revisionsOperation}
+		// The page data written before the revisions were served is replaced by the revision.
+		if len(page.RevisionId) == 0 {
+			prunePaths = append(prunePaths, strings.Join([]string{"pages", s.userId, slideId, pageId}, "/"))
+			prunedBytes += page.Size
+		}
+		cleanupOperation, err := s.cleanupOperation(prunePaths...)
+		if err != nil {
+			return err
+		}
+		operations = append(operations, cleanupOperation)
+		isPruned = len(prunePaths) != 0
 
-	if utf8.RuneCount(getData.Value) != 0 {
-		var slideConfig SlideConfig
+		// The page data has already been written, so the usage is not checked again.
+		usageOperation, err := s.addBytesOperation(written - prunedBytes)
+		if err != nil {
+			return err
+		}
+		operations = append(operations, usageOperation)
+		page.RevisionId = revisionId
+		page.Size = written
 
-		if err := json.Unmarshal(getData.Value, &slideConfig); err != nil {
-			return nil, err
+		searchOperation, err := s.searchIndexOperation(slideId, func(index *searchIndex) {
+			index.set(pageDocumentKey(slideId, pageId), pageTokens(head.Bytes()))
+		})
+		if err != nil {
+			return err
 		}
-		return &slideConfig, nil
+		operations = append(operations, searchOperation)
+
+		return s.commitDetails(slideDetails, etag, operations...)
+	})
+	if err != nil {
+		// The revision has not been committed, so nobody reads it.
+		storageOp.Delete(revisionPath)
+		return err
+	}
+
+	// Delete page data of the old revisions.
+	if isPruned {
+		s.cleanupAfterCommit(storageOp)
 	}
-	// Not exist
-	return &SlideConfig{
-		NumberOfSlides: 0,
-		Slides:         []SlideContent{},
-	}, nil
+	return nil
+}
+
+// Get Slides infomation of user.
+func (s *SlideManager) GetInfo() (*SlideConfig, error) {
+	slideConfig, _, err := s.readInfo()
+	return slideConfig, err
 }
 
 // Get slide detail data.
@@ -168,50 +316,34 @@ func (s *SlideManager) GetInfo() (*SlideConfig, error) {
 // Arguments:
 // - slideId: Id of slide.
 func (s *SlideManager) GetSlideDetails(slideId string) (*SlideData, error) {
-	id := strings.Join([]string{s.userId, slideId}, "|")
+	slideData, _, err := s.GetSlideDetailsWithETag(slideId)
+	return slideData, err
+}
 
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	getSlideData, err := slideInfo.Get(id)
+// Get slide details with its etag.
+// The etag is changed whenever the slide details are changed.
+//
+// Arguments:
+// - slideId: Id of slide.
+func (s *SlideManager) GetSlideDetailsWithETag(slideId string) (*SlideData, string, error) {
+	s, err := s.access(slideId, RoleViewer)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	if utf8.RuneCount(getSlideData.Value) != 0 {
-		var slideData SlideData
-
-		if err := json.Unmarshal(getSlideData.Value, &slideData); err != nil {
-			return nil, err
-		}
-		return &slideData, nil
-	}
-	// Not exist
-	// Create Slide Data
+	var slideData *SlideData
+	var etag string
 
-	slidesConfig, err := s.GetInfo()
+	err = retryOnConflict(func() error {
+		_slideData, _etag, err := s.loadDetails(slideId)
+		slideData = _slideData
+		etag = _etag
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	targetIndex, err := getIndexSlideConfig(*slidesConfig, slideId)
-	if err != nil {
-		return nil, err
-	}
-	slideConfig := slidesConfig.Slides[targetIndex]
-
-	newSlideInfo := &SlideData{
-		NumberOfPages: 0,
-		Pages:         []PageData{},
-		SlideContent:  slideConfig,
-	}
-	body, err := json.Marshal(newSlideInfo)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := slideInfo.Set(id, body); err != nil {
-		return nil, err
-	}
-
-	return newSlideInfo, nil
+	return slideData, etag, nil
 }
 
 // Get page data.
@@ -220,90 +352,160 @@ func (s *SlideManager) GetSlideDetails(slideId string) (*SlideData, error) {
 // - slideId: Id of slide.
 // - pageId: Id of page.
 // - storageOp: storage op instance
-func (s *SlideManager) GetPage(slideId string, pageId string, storageOp storage.StorageOp) ([]byte, error) {
-	dirs := []string{
-		"pages",
-		s.userId,
-		slideId,
-	}
-	isExist, err := storageOp.FileExist(dirs, pageId)
+func (s *SlideManager) GetPage(slideId string, pageId string, storageOp storage.BlobStore) ([]byte, error) {
+	reader, _, err := s.OpenPage(slideId, pageId, storageOp)
 	if err != nil {
 		return nil, err
 	}
+	defer reader.Close()
 
-	if isExist {
-		return storageOp.ReadFile(dirs, pageId)
-	}
-
-	return []byte(""), nil
+	return ioutil.ReadAll(reader)
 }
 
-// Rename slide
+// Open page data to read it as a stream.
+// The page that has not been written is empty.
 //
 // Arguments:
-// - slideId: slide id.
-// - newName: new name(title)
-func (s *SlideManager) Rename(slideId string, newName string) error {
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-
-	getData, err := slideInfo.Get(s.userId)
+// - slideId: Id of slide.
+// - pageId: Id of page.
+// - storageOp: storage op instance
+//
+// Return:
+// - reader io.ReadCloser: page data. Close it after reading.
+// - pageInfo *PageInfo: size and version of the page data.
+func (s *SlideManager) OpenPage(slideId string, pageId string, storageOp storage.BlobStore) (io.ReadCloser, *PageInfo, error) {
+	s, err := s.access(slideId, RoleViewer)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	var slideConfig SlideConfig
-
-	if utf8.RuneCount(getData.Value) == 0 {
-		return fmt.Errorf("the slide does not exist")
-	}
-	if err := json.Unmarshal(getData.Value, &slideConfig); err != nil {
-		return err
+	// The page id is a part of the storage path, so only the page of the slide is read.
+	slideDetails, err := s.GetSlideDetails(slideId)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	targetIndex, err := getIndexSlideConfig(slideConfig, slideId)
+	pageIndex, err := getIndexPage(*slideDetails, pageId)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	slideConfig.Slides[targetIndex].Title = newName
+	page := slideDetails.Pages[pageIndex]
 
-	body, err := json.Marshal(slideConfig)
+	dirs, fileName := s.pageLocation(slideId, page)
+	isExist, err := storageOp.FileExist(dirs, fileName)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	if err := slideInfo.Set(s.userId, body); err != nil {
-		return err
+	if isExist {
+		reader, fileInfo, err := storageOp.OpenFile(dirs, fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		return reader, newPageInfoOf(page, fileInfo), nil
 	}
 
-	// change slide details.
-	id := strings.Join([]string{s.userId, slideId}, "|")
+	return ioutil.NopCloser(strings.NewReader("")), newPageInfo(nil), nil
+}
 
-	_slideData, err := slideInfo.Get(id)
+// Returns the version of the page data.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - pageId: Id of page.
+// - storageOp: storage op instance
+func (s *SlideManager) StatPage(slideId string, pageId string, storageOp storage.BlobStore) (*PageInfo, error) {
+	s, err := s.access(slideId, RoleViewer)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if utf8.RuneCount(_slideData.Value) == 0 {
-		return nil
+	// The page id is a part of the storage path, so only the page of the slide is read.
+	slideDetails, err := s.GetSlideDetails(slideId)
+	if err != nil {
+		return nil, err
 	}
+	pageIndex, err := getIndexPage(*slideDetails, pageId)
+	if err != nil {
+		return nil, err
+	}
+	return s.pageInfo(slideId, slideDetails.Pages[pageIndex], storageOp)
+}
 
-	var slideData SlideData
-
-	if err := json.Unmarshal(_slideData.Value, &slideData); err != nil {
-		return err
+// Returns the storage location of the current page data.
+// It is the committed revision, or `pages/<user>/<slide>/<page>` for the page that has no revision id
+// such as the page written before the revisions were served or the duplicated page.
+func (s *SlideManager) pageLocation(slideId string, page PageData) ([]string, string) {
+	if len(page.RevisionId) != 0 {
+		return s.revisionDirs(slideId, page.PageId), page.RevisionId
 	}
-	slideData.Title = newName
+	return []string{"pages", s.userId, slideId}, page.PageId
+}
 
-	body, err = json.Marshal(slideData)
+// Returns the version of the current page data.
+func (s *SlideManager) pageInfo(slideId string, page PageData, storageOp storage.BlobStore) (*PageInfo, error) {
+	dirs, fileName := s.pageLocation(slideId, page)
+	fileInfo, err := storageOp.Stat(dirs, fileName)
+	if errors.Is(err, storage.ErrNotExist) {
+		return newPageInfo(nil), nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return newPageInfoOf(page, fileInfo), nil
+}
 
-	if err := slideInfo.Set(id, body); err != nil {
+// Rename slide
+//
+// Arguments:
+// - slideId: slide id.
+// - newName: new name(title)
+func (s *SlideManager) Rename(slideId string, newName string) error {
+	s, err := s.access(slideId, RoleEditor)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return retryOnConflict(func() error {
+		slideConfig, etag, err := s.readInfo()
+		if err != nil {
+			return err
+		}
+
+		targetIndex, err := getIndexSlideConfig(*slideConfig, slideId)
+		if err != nil {
+			return err
+		}
+		slideConfig.Slides[targetIndex].Title = newName
+
+		infoOperation, err := upsertOperation(s.userId, slideConfig, etag)
+		if err != nil {
+			return err
+		}
+		searchOperation, err := s.searchIndexOperation(slideId, func(index *searchIndex) {
+			index.set(slideId, tokenize(newName))
+		})
+		if err != nil {
+			return err
+		}
+		operations := []state.Operation{infoOperation, searchOperation}
+
+		// change slide details.
+		slideData, detailsEtag, err := s.readDetails(slideId)
+		if err != nil {
+			return err
+		}
+		if slideData != nil {
+			slideData.Title = newName
+
+			detailsOperation, err := upsertOperation(s.detailsKey(slideId), slideData, detailsEtag)
+			if err != nil {
+				return err
+			}
+			operations = append(operations, detailsOperation)
+		}
+
+		return s.state.Transaction(operations)
+	})
 }
 
 // Swap pages
@@ -313,253 +515,232 @@ func (s *SlideManager) Rename(slideId string, newName string) error {
 // - origin: origin index.
 // - target: target index.
 func (s *SlideManager) SwapPage(slideId string, origin int, target int) error {
-	id := strings.Join([]string{s.userId, slideId}, "|")
-
-	slideData, err := s.GetSlideDetails(slideId)
+	s, err := s.access(slideId, RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	if origin >= len(slideData.Pages) || target >= len(slideData.Pages) || origin < 0 || target < 0 {
-		return fmt.Errorf("the specified index is out of range")
-	}
-
-	buffer := slideData.Pages[origin]
-	slideData.Pages[origin] = slideData.Pages[target]
-	slideData.Pages[target] = buffer
-
-	dateOp := newDateOp()
-	slideData.ChangeDate = dateOp.getDateJST()
+	return retryOnConflict(func() error {
+		slideData, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
 
-	body, err := json.Marshal(slideData)
-	if err != nil {
-		return err
-	}
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	if err := slideInfo.Set(id, []byte(body)); err != nil {
-		return err
-	}
+		if err := swapPages(slideData, origin, target); err != nil {
+			return err
+		}
 
-	if err := s.changedDateUpdate(true, false, slideId); err != nil {
-		return nil
-	}
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
 
-	return nil
+		return s.commitDetails(slideData, etag)
+	})
 }
 
-// Delete slide.
+// Move page to the index.
+// The pages between the old and new index are shifted.
 //
 // Arguments:
 // - slideId: Id of slide.
-// - storageOp: storage op instance
-func (s *SlideManager) Delete(slideId string, storageOp storage.StorageOp) error {
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-
-	// delete slide config
-	getData, err := slideInfo.Get(s.userId)
+// - pageId: Id of page.
+// - newIndex: index after moving.
+func (s *SlideManager) MovePage(slideId string, pageId string, newIndex int) error {
+	s, err := s.access(slideId, RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	var slideConfig SlideConfig
+	return retryOnConflict(func() error {
+		slideData, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
 
-	if utf8.RuneCount(getData.Value) == 0 {
-		return fmt.Errorf("the slide does not exist")
-	}
+		if err := movePage(slideData, pageId, newIndex); err != nil {
+			return err
+		}
 
-	if err := json.Unmarshal(getData.Value, &slideConfig); err != nil {
-		return err
-	}
-	slideConfig.NumberOfSlides--
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
 
-	deleteIndex, err := getIndexSlideConfig(slideConfig, slideId)
-	if err != nil {
-		return err
-	}
-	newSlides := removeSlides(slideConfig.Slides, deleteIndex)
-	slideConfig.Slides = newSlides
+		return s.commitDetails(slideData, etag)
+	})
+}
 
-	body, err := json.Marshal(slideConfig)
+// Set the order of all pages.
+//
+// Arguments:
+// - slideId: Id of slide.
+// - pageIds: Ids of all pages in the new order.
+func (s *SlideManager) SetPageOrder(slideId string, pageIds []string) error {
+	s, err := s.access(slideId, RoleEditor)
 	if err != nil {
 		return err
 	}
 
-	if err := slideInfo.Set(s.userId, body); err != nil {
-		return err
-	}
-
-	// delete slide page info
-	id := strings.Join([]string{s.userId, slideId}, "|")
-	if err := slideInfo.Delete(id); err != nil {
-		return err
-	}
+	return retryOnConflict(func() error {
+		slideData, etag, err := s.loadDetails(slideId)
+		if err != nil {
+			return err
+		}
 
-	// Delete page data.
-	filePath := []string{
-		"pages",
-		s.userId,
-		slideId,
-	}
-	if err := storageOp.Delete(strings.Join(filePath, "/")); err != nil {
-		return err
-	}
+		if err := orderPages(slideData, pageIds); err != nil {
+			return err
+		}
 
-	return nil
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
 
+		return s.commitDetails(slideData, etag)
+	})
 }
 
-// Delete All slide.
+// Delete slide.
+// The slide is moved to the trash and its pages are kept until it is purged.
 //
 // Arguments:
+// - slideId: Id of slide.
 // - storageOp: storage op instance
-func (s *SlideManager) DeleteAll(storageOp storage.StorageOp) error {
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-
-	slideData, err := slideInfo.Get(s.userId)
-	var slideConfig SlideConfig
-
-	if utf8.RuneCount(slideData.Value) == 0 {
-		// If the database with userId as Key does not exist, the slide data is empty.
-		return nil
-	}
-
-	if err := json.Unmarshal(slideData.Value, &slideConfig); err != nil {
+func (s *SlideManager) Delete(slideId string, storageOp storage.BlobStore) error {
+	if _, err := s.access(slideId, RoleOwner); err != nil {
 		return err
 	}
 
-	if err != nil {
-		return err
-	}
-	for _, pageId := range slideConfig.Slides {
-		id := strings.Join([]string{s.userId, pageId.Id}, "|")
-		if err := slideInfo.Delete(id); err != nil {
+	err := retryOnConflict(func() error {
+		slideConfig, etag, err := s.readInfo()
+		if err != nil {
 			return err
 		}
-	}
+		slideConfig.NumberOfSlides--
 
-	if err := slideInfo.Delete(s.userId); err != nil {
-		return err
-	}
+		deleteIndex, err := getIndexSlideConfig(*slideConfig, slideId)
+		if err != nil {
+			return err
+		}
+		slideContent := slideConfig.Slides[deleteIndex]
+		newSlides := removeSlides(slideConfig.Slides, deleteIndex)
+		slideConfig.Slides = newSlides
 
-	// Delete page data.
-	filePath := []string{
-		"pages",
-		s.userId,
-	}
-	if err := storageOp.Delete(strings.Join(filePath, "/")); err != nil {
+		infoOperation, err := upsertOperation(s.userId, slideConfig, etag)
+		if err != nil {
+			return err
+		}
+		trashOperation, err := s.moveToTrashOperation(TrashItem{
+			Type:    trashTypeSlide,
+			SlideId: slideId,
+			Slide:   &slideContent,
+			Index:   deleteIndex,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.state.Transaction([]state.Operation{infoOperation, trashOperation})
+	})
+	if err != nil {
 		return err
 	}
+
+	s.purgeAfterCommit(storageOp)
 	return nil
 }
 
-// Delete page.
+// Delete All slide.
+// All slides are moved to the trash.
 //
 // Arguments:
-// - slideId: Id of slide.
-// - pageId: Id of page.
 // - storageOp: storage op instance
-func (s *SlideManager) DeletePage(slideId string, pageId string, storageOp storage.StorageOp) error {
-	slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-	id := strings.Join([]string{s.userId, slideId}, "|")
-
-	getData, err := slideInfo.Get(id)
-	if err != nil {
-		return err
-	}
-
-	var slideData SlideData
+func (s *SlideManager) DeleteAll(storageOp storage.BlobStore) error {
+	err := retryOnConflict(func() error {
+		slideConfig, etag, err := s.readInfo()
+		if err != nil {
+			return err
+		}
 
-	if utf8.RuneCount(getData.Value) == 0 {
-		return fmt.Errorf("the slide does not exist")
-	}
+		if len(slideConfig.Slides) == 0 {
+			// If the database with userId as Key does not exist, the slide data is empty.
+			return nil
+		}
 
-	if err := json.Unmarshal(getData.Value, &slideData); err != nil {
-		return err
-	}
-	slideData.NumberOfPages--
-	dateOp := newDateOp()
-	slideData.ChangeDate = dateOp.getDateJST()
+		items := []TrashItem{}
+		for index := range slideConfig.Slides {
+			items = append(items, TrashItem{
+				Type:    trashTypeSlide,
+				SlideId: slideConfig.Slides[index].Id,
+				Slide:   &slideConfig.Slides[index],
+				Index:   index,
+			})
+		}
+		trashOperation, err := s.moveToTrashOperation(items...)
+		if err != nil {
+			return err
+		}
 
-	deleteIndex, err := getIndexPage(slideData, pageId)
-	if err != nil {
-		return err
-	}
-	newPages := removePage(slideData.Pages, deleteIndex)
-	slideData.Pages = newPages
+		// If a slide has been created meanwhile, move it too.
+		infoOperation := state.Operation{
+			Type: state.OperationDelete,
+			Key:  s.userId,
+			Etag: etag,
+		}
 
-	body, err := json.Marshal(slideData)
+		return s.state.Transaction([]state.Operation{infoOperation, trashOperation})
+	})
 	if err != nil {
 		return err
 	}
 
-	if err := slideInfo.Set(id, body); err != nil {
-		return err
-	}
-
-	if err := s.changedDateUpdate(true, false, slideId); err != nil {
-		return err
-	}
-
-	filePath := []string{
-		"pages",
-		s.userId,
-		slideId,
-		pageId,
-	}
-	if err := storageOp.Delete(strings.Join(filePath, "/")); err != nil {
-		return err
-	}
+	s.purgeAfterCommit(storageOp)
 	return nil
 }
 
-// Update `change_date`
+// Delete page.
+// The page is moved to the trash.
 //
 // Arguments:
-// - isInfo: If set to true, the user's slide database will be updated.
-// - isDetails: If set to true, the detailed database for that slide will be updated.
 // - slideId: Id of slide.
-func (s *SlideManager) changedDateUpdate(isInfo bool, isDetails bool, slideId string) error {
-	dateOp := newDateOp()
-	if isInfo {
-		slideInfo, err := s.GetInfo()
-		if err != nil {
-			return err
-		}
-		targetIndex, err := getIndexSlideConfig(*slideInfo, slideId)
+// - pageId: Id of page.
+// - storageOp: storage op instance
+func (s *SlideManager) DeletePage(slideId string, pageId string, storageOp storage.BlobStore) error {
+	s, err := s.access(slideId, RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	err = retryOnConflict(func() error {
+		slideData, etag, err := s.loadDetails(slideId)
 		if err != nil {
 			return err
 		}
-		slideInfo.Slides[targetIndex].ChangeDate = dateOp.getDateJST()
 
-		body, err := json.Marshal(slideInfo)
+		deleteIndex, err := getIndexPage(*slideData, pageId)
 		if err != nil {
 			return err
 		}
 
-		_slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-		if err := _slideInfo.Set(s.userId, body); err != nil {
-			return err
-		}
-	}
+		slideData.NumberOfPages--
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
 
-	if isDetails {
-		id := strings.Join([]string{s.userId, slideId}, "|")
+		pageData := slideData.Pages[deleteIndex]
+		newPages := removePage(slideData.Pages, deleteIndex)
+		slideData.Pages = newPages
 
-		slideDetails, err := s.GetSlideDetails(slideId)
-		if err != nil {
-			return err
-		}
-		slideDetails.ChangeDate = dateOp.getDateJST()
-
-		body, err := json.Marshal(slideDetails)
+		trashOperation, err := s.moveToTrashOperation(TrashItem{
+			Type:    trashTypePage,
+			SlideId: slideId,
+			Page:    &pageData,
+			Index:   deleteIndex,
+		})
 		if err != nil {
 			return err
 		}
 
-		slideInfo := state.NewState(s.client, &s.ctx, slideInfoState)
-		if err := slideInfo.Set(id, body); err != nil {
-			return err
-		}
+		return s.commitDetails(slideData, etag, trashOperation)
+	})
+	if err != nil {
+		return err
 	}
+
+	s.purgeAfterCommit(storageOp)
 	return nil
 }
@@ -0,0 +1,161 @@
+package slide
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Returns the page ids of the slide in order.
+func pageOrder(t *testing.T, s *SlideManager, slideId string) []string {
+	t.Helper()
+	slideData, err := s.GetSlideDetails(slideId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slideData.NumberOfPages != len(slideData.Pages) {
+		t.Errorf("number_of_pages: got %d, want %d", slideData.NumberOfPages, len(slideData.Pages))
+	}
+	pageIds := []string{}
+	for _, page := range slideData.Pages {
+		pageIds = append(pageIds, page.PageId)
+	}
+	return pageIds
+}
+
+// Returns the slide ids of the user in order.
+func slideOrder(t *testing.T, s *SlideManager) []string {
+	t.Helper()
+	slideConfig, err := s.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slideConfig.NumberOfSlides != len(slideConfig.Slides) {
+		t.Errorf("number_of_slides: got %d, want %d", slideConfig.NumberOfSlides, len(slideConfig.Slides))
+	}
+	slideIds := []string{}
+	for _, slide := range slideConfig.Slides {
+		slideIds = append(slideIds, slide.Id)
+	}
+	return slideIds
+}
+
+// Returns ids without the index.
+func without(ids []string, index int) []string {
+	result := append([]string{}, ids[:index]...)
+	return append(result, ids[index+1:]...)
+}
+
+func TestDeletePageKeepsOrder(t *testing.T) {
+	cases := []struct {
+		name  string
+		index int
+	}{
+		{name: "first", index: 0},
+		{name: "middle", index: 2},
+		{name: "last", index: 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, storageOp := newTestManager(t, "user")
+			slideId, pageIds := createTestSlide(t, s, 5)
+
+			if err := s.DeletePage(slideId, pageIds[c.index], storageOp); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := pageOrder(t, s, slideId), without(pageIds, c.index); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeleteKeepsSlideOrder(t *testing.T) {
+	cases := []struct {
+		name  string
+		index int
+	}{
+		{name: "first", index: 0},
+		{name: "middle", index: 1},
+		{name: "last", index: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, storageOp := newTestManager(t, "user")
+			slideIds := []string{}
+			for index := 0; index < 3; index++ {
+				slideId, _ := createTestSlide(t, s, 1)
+				slideIds = append(slideIds, slideId)
+			}
+
+			if err := s.Delete(slideIds[c.index], storageOp); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := slideOrder(t, s), without(slideIds, c.index); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+
+			// The slide is restored to its original position.
+			trash, err := s.GetTrash(storageOp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RestoreTrash(trash.Items[0].Id); err != nil {
+				t.Fatal(err)
+			}
+			if got := slideOrder(t, s); !reflect.DeepEqual(got, slideIds) {
+				t.Errorf("after restore: got %v, want %v", got, slideIds)
+			}
+		})
+	}
+}
+
+func TestSwapPage(t *testing.T) {
+	cases := []struct {
+		name   string
+		origin int
+		target int
+		// Indexes of the created pages after swapping.
+		want []int
+		err  error
+	}{
+		{name: "first and last", origin: 0, target: 3, want: []int{3, 1, 2, 0}},
+		{name: "adjacent", origin: 2, target: 1, want: []int{0, 2, 1, 3}},
+		{name: "same", origin: 2, target: 2, want: []int{0, 1, 2, 3}},
+		{name: "out of range", origin: 0, target: 4, want: []int{0, 1, 2, 3}, err: ErrIndexOutOfRange},
+		{name: "negative", origin: -1, target: 0, want: []int{0, 1, 2, 3}, err: ErrIndexOutOfRange},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, _ := newTestManager(t, "user")
+			slideId, pageIds := createTestSlide(t, s, 4)
+
+			if err := s.SwapPage(slideId, c.origin, c.target); !errors.Is(err, c.err) {
+				t.Fatalf("got %v, want %v", err, c.err)
+			}
+			want := []string{}
+			for _, index := range c.want {
+				want = append(want, pageIds[index])
+			}
+			if got := pageOrder(t, s, slideId); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeletePageWithoutDetails(t *testing.T) {
+	s, storageOp := newTestManager(t, "user")
+	slideId, err := s.Create("slide")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The details have not been written, and the error is the same as the other page operations.
+	if err := s.DeletePage(slideId, "unknown", storageOp); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("got %v, want ErrPageNotFound", err)
+	}
+	if err := s.SetPage([]byte("data"), slideId, "unknown", storageOp); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("set page: got %v, want ErrPageNotFound", err)
+	}
+}
@@ -1,5 +1,7 @@
 package slide
 
+import "time"
+
 // Detailed information for each slide.
 type SlideData struct {
 	NumberOfPages int        `json:"number_of_pages"`
@@ -10,6 +12,11 @@ type SlideData struct {
 type PageData struct {
 	PageId string `json:"page_id"`
 	Type   string `json:"type"`
+	// Bytes of the page data.
+	Size int64 `json:"size"`
+	// Id of the revision that is the current page data.
+	// Empty if the page data is in `pages/<user>/<slide>/<page>`.
+	RevisionId string `json:"revision_id,omitempty"`
 }
 
 // Information for each slide.
@@ -18,10 +25,202 @@ type SlideContent struct {
 	Id         string `json:"id"`
 	CreateDate string `json:"create_date"`
 	ChangeDate string `json:"change_date"`
+	// Owner and the role of the user for the slide shared with the user.
+	// They are empty for the user's own slide.
+	OwnerId string `json:"owner_id,omitempty"`
+	Role    Role   `json:"role,omitempty"`
 }
 
 // Describe the slide information possessed by the user.
 type SlideConfig struct {
+	// Number of the user's own slides. The shared slides are not counted.
 	NumberOfSlides int            `json:"number_of_slides"`
 	Slides         []SlideContent `json:"slides"`
 }
+
+// Saved revision of the page data.
+type Revision struct {
+	Number int    `json:"number"`
+	Id     string `json:"id"`
+	Date   string `json:"date"`
+	Size   int    `json:"size"`
+}
+
+// Revisions of the page, oldest first.
+type PageRevisions struct {
+	LatestNumber int        `json:"latest_number"`
+	Revisions    []Revision `json:"revisions"`
+}
+
+// Deleted slide or page.
+type TrashItem struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+	// Slide of the slide and the page.
+	SlideId string        `json:"slide_id"`
+	Slide   *SlideContent `json:"slide,omitempty"`
+	Page    *PageData     `json:"page,omitempty"`
+	// Index in the slides or pages before deletion.
+	Index      int    `json:"index"`
+	DeleteDate string `json:"delete_date"`
+}
+
+// Trash of the user.
+type Trash struct {
+	Items []TrashItem `json:"items"`
+}
+
+// Options of listing slides.
+type ListOptions struct {
+	// Cursor returned as NextCursor. Empty for the first page.
+	Cursor string
+	// Max number of slides. All slides if zero.
+	Limit int
+	// `title`, `create_date` or `change_date`. The order of creation if empty.
+	SortBy     string
+	Descending bool
+	// Range of the dates formatted as `20060102150405`. Not filtered if empty.
+	CreatedAfter  string
+	CreatedBefore string
+	ChangedAfter  string
+	ChangedBefore string
+}
+
+// Page of the slides.
+type SlideList struct {
+	Slides []SlideContent `json:"slides"`
+	// Number of slides matching the filter.
+	TotalCount int `json:"total_count"`
+	// Cursor of the next page. Empty if it is the last page.
+	NextCursor string `json:"next_cursor"`
+}
+
+// Version of the page data.
+type PageInfo struct {
+	Size int64
+	// Changed whenever the page is written. `empty` if the page has not been written.
+	ETag string
+	// Zero if the page has not been written.
+	ModTime time.Time
+}
+
+// Slide or page matching the search query.
+type SearchHit struct {
+	SlideId string `json:"slide_id"`
+	Title   string `json:"title"`
+	// Empty if the title matches.
+	PageId  string `json:"page_id,omitempty"`
+	Snippet string `json:"snippet"`
+	// Ranges of the matched words in the snippet.
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Range in the snippet by the rune offset. End is exclusive.
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Result of the search.
+type SearchResult struct {
+	Hits []SearchHit `json:"hits"`
+}
+
+// Operation in the batch.
+// The page id `$<n>` refers to the page created by the n-th operation in the same batch.
+type BatchOperation struct {
+	// `create_page`, `set_page`, `delete_page`, `swap`, `move_page`, `set_order` or `rename`.
+	Op       string   `json:"op"`
+	PageId   string   `json:"page_id,omitempty"`
+	PageType string   `json:"page_type,omitempty"`
+	Data     string   `json:"data,omitempty"`
+	Origin   int      `json:"origin,omitempty"`
+	Target   int      `json:"target,omitempty"`
+	Index    int      `json:"index,omitempty"`
+	PageIds  []string `json:"page_ids,omitempty"`
+	Title    string   `json:"title,omitempty"`
+}
+
+// Result of the operation in the batch.
+type BatchOperationResult struct {
+	Op string `json:"op"`
+	// `ok`, `failed` or `skipped`.
+	Status string `json:"status"`
+	// Id of the created or written page.
+	PageId string `json:"page_id,omitempty"`
+	// Error of the failed operation.
+	Err error `json:"-"`
+}
+
+// Result of the batch.
+type BatchResult struct {
+	// false if no operation has been applied.
+	Applied bool                   `json:"applied"`
+	Results []BatchOperationResult `json:"results"`
+}
+
+// User that the slide is shared with.
+type Grant struct {
+	UserId string `json:"user_id"`
+	// `viewer` or `editor`.
+	Role      Role   `json:"role"`
+	GrantDate string `json:"grant_date"`
+}
+
+// Users that the slide is shared with.
+type SlideACL struct {
+	Grants []Grant `json:"grants"`
+}
+
+// Slide of another user shared with the user.
+type SharedSlide struct {
+	OwnerId string `json:"owner_id"`
+	SlideId string `json:"slide_id"`
+	Role    Role   `json:"role"`
+}
+
+// Slides shared with the user.
+type SharedSlides struct {
+	Slides []SharedSlide `json:"slides"`
+}
+
+// Public read-only link of the slide.
+type ShareLink struct {
+	Id         string `json:"id"`
+	SlideId    string `json:"slide_id"`
+	CreateDate string `json:"create_date"`
+	// Empty if the link does not expire.
+	ExpireDate  string `json:"expire_date,omitempty"`
+	HasPassword bool   `json:"has_password"`
+}
+
+// Active links of the slide.
+type ShareLinks struct {
+	Links []ShareLink `json:"links"`
+}
+
+// Link returned at the creation.
+// The token is in the url of the link, and it can not be read again.
+type CreatedShareLink struct {
+	Token string `json:"token"`
+	ShareLink
+}
+
+// Slide read by the link. The page data is opened by OpenLinkPage.
+type LinkedSlide struct {
+	OwnerId string
+	Slide   *SlideData
+}
+
+// Usage of the user and the limits.
+// The limit is 0 if it is unlimited.
+type Usage struct {
+	Slides    int `json:"slides"`
+	MaxSlides int `json:"max_slides"`
+	// Total bytes of the page data of all kept revisions, including the pages in the trash.
+	Bytes    int64 `json:"bytes"`
+	MaxBytes int64 `json:"max_bytes"`
+	// Limits of each slide and each page.
+	MaxPagesPerSlide int   `json:"max_pages_per_slide"`
+	MaxPageSize      int64 `json:"max_page_size"`
+}
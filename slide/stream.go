@@ -0,0 +1,77 @@
+package slide
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/hello-slide/slide-manager/storage"
+)
+
+// Reader that fails with ErrPageTooLarge when more than limit bytes are read.
+type sizeLimitReader struct {
+	reader io.Reader
+	limit  int64
+	size   int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	if r.size > r.limit {
+		return n, pageTooLarge()
+	}
+	return n, err
+}
+
+// Writer that keeps the first limit bytes and discards the rest.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.Len(); rest > 0 {
+		if len(p) > rest {
+			b.Buffer.Write(p[:rest])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// Returns ErrPageTooLarge with the max size.
+func pageTooLarge() error {
+	return &Error{
+		Err:     ErrPageTooLarge,
+		Message: fmt.Sprintf("the page data must be at most %d bytes", maxPageSize),
+	}
+}
+
+// ETag of the page that has not been written.
+const emptyPageETag string = "empty"
+
+// Returns the page info of the file. fileInfo is nil if the page has not been written.
+func newPageInfo(fileInfo *storage.FileInfo) *PageInfo {
+	if fileInfo == nil {
+		return &PageInfo{
+			ETag: emptyPageETag,
+		}
+	}
+	return &PageInfo{
+		Size:    fileInfo.Size,
+		ETag:    fileInfo.Version,
+		ModTime: fileInfo.ModTime,
+	}
+}
+
+// Returns the page info of the current page data.
+// The ETag of the page with the revision is the revision id, because the revision is never changed.
+func newPageInfoOf(page PageData, fileInfo *storage.FileInfo) *PageInfo {
+	pageInfo := newPageInfo(fileInfo)
+	if fileInfo != nil && len(page.RevisionId) != 0 {
+		pageInfo.ETag = page.RevisionId
+	}
+	return pageInfo
+}
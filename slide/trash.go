@@ -0,0 +1,440 @@
+package slide
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hello-slide/slide-manager/state"
+	"github.com/hello-slide/slide-manager/storage"
+	"github.com/hello-slide/slide-manager/utils"
+)
+
+const (
+	trashTypeSlide string = "slide"
+	trashTypePage  string = "page"
+)
+
+// Number of the shards of the users who have the trash.
+const trashUsersShards = 16
+
+// Users who have items in the trash, to purge the trash of the users who do not come back.
+// It is sharded by the hash of the user id.
+type trashUsers struct {
+	UserIds []string `json:"user_ids"`
+}
+
+// Returns the state key of the shard of the users who have the trash.
+func trashUsersKey(shard int) string {
+	return fmt.Sprintf("trash-users|%x", shard)
+}
+
+// Returns the shard of the user.
+func trashUsersShard(userId string) int {
+	hash := sha256.Sum256([]byte(userId))
+	return int(hash[0]) % trashUsersShards
+}
+
+// Returns the state key of the trash.
+func (s *SlideManager) trashKey() string {
+	return strings.Join([]string{s.userId, "trash"}, "|")
+}
+
+// Read the trash with its etag.
+func (s *SlideManager) readTrash() (*Trash, string, error) {
+	getData, err := s.state.Get(s.trashKey())
+	if err != nil {
+		return nil, "", err
+	}
+
+	trash := Trash{
+		Items: []TrashItem{},
+	}
+	if utf8.RuneCount(getData.Value) != 0 {
+		if err := json.Unmarshal(getData.Value, &trash); err != nil {
+			return nil, "", err
+		}
+	}
+	return &trash, getData.Etag, nil
+}
+
+// Returns the transaction operation that writes the trash.
+// The empty trash is deleted.
+func (s *SlideManager) trashOperation(trash *Trash, etag string) (state.Operation, error) {
+	if len(trash.Items) == 0 {
+		return state.Operation{
+			Type: state.OperationDelete,
+			Key:  s.trashKey(),
+			Etag: etag,
+		}, nil
+	}
+	return upsertOperation(s.trashKey(), trash, etag)
+}
+
+// Returns the transaction operation that adds items to the trash.
+// The user is added to the users who have the trash before the trash is written.
+func (s *SlideManager) moveToTrashOperation(items ...TrashItem) (state.Operation, error) {
+	// The trash is read first, so that the transaction fails if the user is removed after registering.
+	trash, etag, err := s.readTrash()
+	if err != nil {
+		return state.Operation{}, err
+	}
+	if err := s.registerTrashUser(); err != nil {
+		return state.Operation{}, err
+	}
+
+	dateOp := newDateOp()
+	for _, item := range items {
+		id, err := utils.CreateId(item.SlideId)
+		if err != nil {
+			return state.Operation{}, err
+		}
+		item.Id = id
+		item.DeleteDate = dateOp.getDateJST()
+		trash.Items = append(trash.Items, item)
+	}
+	return s.trashOperation(trash, etag)
+}
+
+// Add the user to the users who have the trash.
+func (s *SlideManager) registerTrashUser() error {
+	key := trashUsersKey(trashUsersShard(s.userId))
+	return retryOnConflict(func() error {
+		users := &trashUsers{
+			UserIds: []string{},
+		}
+		etag, err := s.readJSON(key, users)
+		if err != nil {
+			return err
+		}
+		for _, userId := range users.UserIds {
+			if userId == s.userId {
+				return nil
+			}
+		}
+		users.UserIds = append(users.UserIds, s.userId)
+		return s.writeDocument(key, users, etag)
+	})
+}
+
+// Remove the user from the users who have the trash if the trash is empty.
+// The trash is written with its etag in the same transaction,
+// so the user who has moved an item to the trash meanwhile is not removed.
+func (s *SlideManager) unregisterTrashUser() error {
+	key := trashUsersKey(trashUsersShard(s.userId))
+	return retryOnConflict(func() error {
+		trash, trashEtag, err := s.readTrash()
+		if err != nil {
+			return err
+		}
+		if len(trash.Items) != 0 {
+			return nil
+		}
+
+		users := &trashUsers{
+			UserIds: []string{},
+		}
+		etag, err := s.readJSON(key, users)
+		if err != nil {
+			return err
+		}
+		userIds := []string{}
+		for _, userId := range users.UserIds {
+			if userId != s.userId {
+				userIds = append(userIds, userId)
+			}
+		}
+		if len(userIds) == len(users.UserIds) {
+			return nil
+		}
+		users.UserIds = userIds
+
+		trashOperation, err := upsertOperation(s.trashKey(), trash, trashEtag)
+		if err != nil {
+			return err
+		}
+		usersOperation, err := upsertOperation(key, users, etag)
+		if err != nil {
+			return err
+		}
+		return s.state.Transaction([]state.Operation{trashOperation, usersOperation})
+	})
+}
+
+// Purge the trash items of all users that have been in the trash longer than `TRASH_RETENTION`.
+// It is called periodically, so that the trash of the user who does not come back is purged.
+// The user of s is not used. The failure of a user is logged and the others are purged.
+//
+// Arguments:
+// - storageOp: storage op instance
+func (s *SlideManager) PurgeAllTrash(storageOp storage.BlobStore) error {
+	for shard := 0; shard < trashUsersShards; shard++ {
+		users := &trashUsers{
+			UserIds: []string{},
+		}
+		if _, err := s.readJSON(trashUsersKey(shard), users); err != nil {
+			return err
+		}
+
+		for _, userId := range users.UserIds {
+			user := s.withUser(userId)
+			if err := user.PurgeTrash(storageOp, trashRetention); err != nil {
+				log.Printf("failed to purge the trash of %s: %v", userId, err)
+				continue
+			}
+			if err := user.unregisterTrashUser(); err != nil {
+				log.Printf("failed to unregister the trash of %s: %v", userId, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Purge the expired trash items after the deletion has been committed.
+// The deletion has succeeded, so the failure is only logged and the items are purged later.
+func (s *SlideManager) purgeAfterCommit(storageOp storage.BlobStore) {
+	if err := s.PurgeTrash(storageOp, trashRetention); err != nil {
+		log.Printf("failed to purge the trash of %s: %v", s.userId, err)
+	}
+}
+
+// Get slides and pages in the trash.
+// The items older than `TRASH_RETENTION` are purged before.
+//
+// Arguments:
+// - storageOp: storage op instance
+func (s *SlideManager) GetTrash(storageOp storage.BlobStore) (*Trash, error) {
+	if err := s.PurgeTrash(storageOp, trashRetention); err != nil {
+		return nil, err
+	}
+
+	trash, _, err := s.readTrash()
+	return trash, err
+}
+
+// Restore the slide or page in the trash to its original position.
+// A page can not be restored while its slide is in the trash.
+//
+// Arguments:
+// - trashId: Id of the trash item.
+func (s *SlideManager) RestoreTrash(trashId string) error {
+	return retryOnConflict(func() error {
+		trash, trashEtag, err := s.readTrash()
+		if err != nil {
+			return err
+		}
+
+		targetIndex := -1
+		for index, item := range trash.Items {
+			if item.Id == trashId {
+				targetIndex = index
+				break
+			}
+		}
+		if targetIndex < 0 {
+			return ErrTrashItemNotFound
+		}
+		item := trash.Items[targetIndex]
+		trash.Items = append(trash.Items[:targetIndex], trash.Items[targetIndex+1:]...)
+
+		trashOperation, err := s.trashOperation(trash, trashEtag)
+		if err != nil {
+			return err
+		}
+
+		if item.Type == trashTypeSlide {
+			slideConfig, etag, err := s.readInfo()
+			if err != nil {
+				return err
+			}
+			if err := checkSlidesQuota(slideConfig); err != nil {
+				return err
+			}
+			index := item.Index
+			if index > len(slideConfig.Slides) {
+				index = len(slideConfig.Slides)
+			}
+			slideConfig.Slides = append(slideConfig.Slides[:index], append([]SlideContent{*item.Slide}, slideConfig.Slides[index:]...)...)
+			slideConfig.NumberOfSlides++
+
+			infoOperation, err := upsertOperation(s.userId, slideConfig, etag)
+			if err != nil {
+				return err
+			}
+			return s.state.Transaction([]state.Operation{infoOperation, trashOperation})
+		}
+
+		slideData, etag, err := s.readDetails(item.SlideId)
+		if err != nil {
+			return err
+		}
+		if slideData == nil {
+			return &Error{
+				Err:     ErrSlideNotFound,
+				Message: "the slide of the page does not exist, restore the slide first",
+			}
+		}
+		if err := checkPagesQuota(slideData); err != nil {
+			return err
+		}
+		index := item.Index
+		if index > len(slideData.Pages) {
+			index = len(slideData.Pages)
+		}
+		slideData.Pages = append(slideData.Pages[:index], append([]PageData{*item.Page}, slideData.Pages[index:]...)...)
+		slideData.NumberOfPages++
+
+		dateOp := newDateOp()
+		slideData.ChangeDate = dateOp.getDateJST()
+
+		return s.commitDetails(slideData, etag, trashOperation)
+	})
+}
+
+// Permanently delete all slides and pages in the trash.
+//
+// Arguments:
+// - storageOp: storage op instance
+func (s *SlideManager) EmptyTrash(storageOp storage.BlobStore) error {
+	return s.purge(storageOp, func(item TrashItem) bool {
+		return true
+	})
+}
+
+// Permanently delete slides and pages that have been in the trash longer than retention.
+//
+// Arguments:
+// - storageOp: storage op instance
+// - retention: period to keep the items.
+func (s *SlideManager) PurgeTrash(storageOp storage.BlobStore, retention time.Duration) error {
+	now := time.Now()
+	return s.purge(storageOp, func(item TrashItem) bool {
+		deleteDate, err := parseDateJST(item.DeleteDate)
+		if err != nil {
+			return true
+		}
+		return now.Sub(deleteDate) > retention
+	})
+}
+
+// Permanently delete the trash items that isTarget returns true.
+// The pages of a deleted slide are also deleted.
+func (s *SlideManager) purge(storageOp storage.BlobStore, isTarget func(item TrashItem) bool) error {
+	isPurged := false
+
+	err := retryOnConflict(func() error {
+		trash, trashEtag, err := s.readTrash()
+		if err != nil {
+			return err
+		}
+
+		purgedSlides := map[string]bool{}
+		for _, item := range trash.Items {
+			if item.Type == trashTypeSlide && isTarget(item) {
+				purgedSlides[item.SlideId] = true
+			}
+		}
+
+		operations := []state.Operation{}
+		paths := []string{}
+		remains := []TrashItem{}
+		// Document keys of the purged pages by the slide id.
+		documentKeys := map[string][]string{}
+		// Bytes of the page data deleted from the usage.
+		var freedBytes int64
+		for _, item := range trash.Items {
+			if !isTarget(item) && !purgedSlides[item.SlideId] {
+				remains = append(remains, item)
+				continue
+			}
+
+			if item.Type == trashTypeSlide {
+				slideData, _, err := s.readDetails(item.SlideId)
+				if err != nil {
+					return err
+				}
+				if slideData != nil {
+					for _, page := range slideData.Pages {
+						pageBytes, err := s.storedPageBytes(item.SlideId, page)
+						if err != nil {
+							return err
+						}
+						freedBytes += pageBytes
+					}
+				}
+				detailsOperations, err := s.deleteDetailsOperations(item.SlideId)
+				if err != nil {
+					return err
+				}
+				operations = append(operations, detailsOperations...)
+				paths = append(paths,
+					strings.Join([]string{"pages", s.userId, item.SlideId}, "/"),
+					strings.Join([]string{"revisions", s.userId, item.SlideId}, "/"))
+				continue
+			}
+
+			pageBytes, err := s.storedPageBytes(item.SlideId, *item.Page)
+			if err != nil {
+				return err
+			}
+			freedBytes += pageBytes
+			operations = append(operations, state.Operation{
+				Type: state.OperationDelete,
+				Key:  s.revisionsKey(item.SlideId, item.Page.PageId),
+			})
+			// The search index of the purged slide is deleted.
+			if !purgedSlides[item.SlideId] {
+				documentKeys[item.SlideId] = append(documentKeys[item.SlideId], pageDocumentKey(item.SlideId, item.Page.PageId))
+			}
+			paths = append(paths,
+				strings.Join([]string{"pages", s.userId, item.SlideId, item.Page.PageId}, "/"),
+				strings.Join(s.revisionDirs(item.SlideId, item.Page.PageId), "/"))
+		}
+		if len(remains) == len(trash.Items) {
+			isPurged = false
+			return nil
+		}
+		trash.Items = remains
+
+		trashOperation, err := s.trashOperation(trash, trashEtag)
+		if err != nil {
+			return err
+		}
+		cleanupOperation, err := s.cleanupOperation(paths...)
+		if err != nil {
+			return err
+		}
+		for slideId, keys := range documentKeys {
+			searchOperation, err := s.searchIndexOperation(slideId, func(index *searchIndex) {
+				for _, key := range keys {
+					index.remove(key)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			operations = append(operations, searchOperation)
+		}
+
+		usageOperation, err := s.addBytesOperation(-freedBytes)
+		if err != nil {
+			return err
+		}
+
+		isPurged = true
+		return s.state.Transaction(append(operations, trashOperation, cleanupOperation, usageOperation))
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delete page data.
+	if isPurged {
+		s.cleanupAfterCommit(storageOp)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package slide
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPurgeAllTrash(t *testing.T) {
+	defer func(value time.Duration) { trashRetention = value }(trashRetention)
+	trashRetention = time.Hour
+
+	alice, storageOp := newTestManager(t, "alice")
+	bob := alice.withUser("bob")
+	aliceSlideId, alicePageIds := createTestSlide(t, alice, 2)
+	bobSlideId, _ := createTestSlide(t, bob, 1)
+
+	if err := alice.DeletePage(aliceSlideId, alicePageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.Delete(bobSlideId, storageOp); err != nil {
+		t.Fatal(err)
+	}
+
+	// The items have not expired.
+	if err := alice.withUser("").PurgeAllTrash(storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertTrashItems(t, alice, 1)
+	assertTrashItems(t, bob, 1)
+
+	// The users who have not come back are purged.
+	trashRetention = -time.Hour
+	if err := alice.withUser("").PurgeAllTrash(storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertTrashItems(t, alice, 0)
+	assertTrashItems(t, bob, 0)
+
+	for shard := 0; shard < trashUsersShards; shard++ {
+		users := &trashUsers{}
+		if _, err := alice.readJSON(trashUsersKey(shard), users); err != nil {
+			t.Fatal(err)
+		}
+		if len(users.UserIds) != 0 {
+			t.Errorf("shard %d: got %v, want no users", shard, users.UserIds)
+		}
+	}
+
+	// The user is registered again.
+	if err := alice.DeletePage(aliceSlideId, alicePageIds[1], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	users := &trashUsers{}
+	if _, err := alice.readJSON(trashUsersKey(trashUsersShard("alice")), users); err != nil {
+		t.Fatal(err)
+	}
+	if len(users.UserIds) != 1 || users.UserIds[0] != "alice" {
+		t.Errorf("got %v, want [alice]", users.UserIds)
+	}
+}
+
+func assertTrashItems(t *testing.T, s *SlideManager, want int) {
+	t.Helper()
+	trash, _, err := s.readTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Items) != want {
+		t.Errorf("%s: got %d trash items, want %d", s.userId, len(trash.Items), want)
+	}
+}
@@ -0,0 +1,96 @@
+package slide
+
+import (
+	"strings"
+
+	"github.com/hello-slide/slide-manager/state"
+)
+
+// Usage of the user tracked in the state store.
+// It is changed in the same transaction as the page data.
+type storedUsage struct {
+	Bytes int64 `json:"bytes"`
+}
+
+// Returns the state key of the usage.
+func (s *SlideManager) usageKey() string {
+	return strings.Join([]string{s.userId, "usage"}, "|")
+}
+
+// Read the usage with its etag.
+// If it does not exist, returns zero usage and empty etag.
+func (s *SlideManager) readUsage() (*storedUsage, string, error) {
+	usage := &storedUsage{}
+	etag, err := s.readJSON(s.usageKey(), usage)
+	return usage, etag, err
+}
+
+// Returns the transaction operation that adds delta to the stored bytes.
+func (s *SlideManager) addBytesOperation(delta int64) (state.Operation, error) {
+	usage, etag, err := s.readUsage()
+	if err != nil {
+		return state.Operation{}, err
+	}
+	usage.Bytes += delta
+	if usage.Bytes < 0 {
+		usage.Bytes = 0
+	}
+	return upsertOperation(s.usageKey(), usage, etag)
+}
+
+// Returns the quota error if the stored bytes exceed the limit by adding delta.
+func (s *SlideManager) checkBytesQuota(delta int64) error {
+	if maxStorageBytes <= 0 || delta <= 0 {
+		return nil
+	}
+	usage, _, err := s.readUsage()
+	if err != nil {
+		return err
+	}
+	return checkBytesLimit(usage.Bytes + delta)
+}
+
+// Returns the quota error if the stored bytes are over the limit.
+func checkBytesLimit(bytes int64) error {
+	if maxStorageBytes > 0 && bytes > maxStorageBytes {
+		return quotaExceeded("the page data is limited to %d bytes in total", maxStorageBytes)
+	}
+	return nil
+}
+
+// Returns the quota error if a slide can not be added to the slides.
+func checkSlidesQuota(slideConfig *SlideConfig) error {
+	if maxSlides > 0 && len(slideConfig.Slides) >= maxSlides {
+		return quotaExceeded("the number of slides is limited to %d", maxSlides)
+	}
+	return nil
+}
+
+// Returns the quota error if a page can not be added to the slide.
+func checkPagesQuota(slideData *SlideData) error {
+	if maxPagesPerSlide > 0 && len(slideData.Pages) >= maxPagesPerSlide {
+		return quotaExceeded("the number of pages of a slide is limited to %d", maxPagesPerSlide)
+	}
+	return nil
+}
+
+// Get the usage of the user and the limits.
+func (s *SlideManager) GetUsage() (*Usage, error) {
+	slideConfig, _, err := s.readInfo()
+	if err != nil {
+		return nil, err
+	}
+	usage, _, err := s.readUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Usage{
+		Slides:           len(slideConfig.Slides),
+		MaxSlides:        maxSlides,
+		Bytes:            usage.Bytes,
+		MaxBytes:         maxStorageBytes,
+		MaxPagesPerSlide: maxPagesPerSlide,
+		MaxPageSize:      maxPageSize,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package slide
+
+import (
+	"testing"
+)
+
+// Check the stored bytes of the user.
+func assertUsedBytes(t *testing.T, s *SlideManager, want int64) {
+	t.Helper()
+	usage, err := s.GetUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Bytes != want {
+		t.Errorf("bytes: got %d, want %d", usage.Bytes, want)
+	}
+}
+
+func TestUsageCountsRevisions(t *testing.T) {
+	defer func(value int) { maxRevisions = value }(maxRevisions)
+	maxRevisions = 2
+
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 2)
+
+	// Each revision is counted until it is pruned.
+	if err := s.SetPage([]byte("1"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 1)
+	if err := s.SetPage([]byte("22"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 3)
+	if err := s.SetPage([]byte("333"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 5)
+
+	// The batch counts the committed revisions only.
+	_, err := s.Batch(slideId, []BatchOperation{
+		{Op: "set_page", PageId: pageIds[1], Data: "xxxxxxxxxx"},
+		{Op: "set_page", PageId: pageIds[1], Data: "4444"},
+		{Op: "set_page", PageId: pageIds[0], Data: "55555"},
+	}, true, storageOp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Page 0 keeps "333" and "55555", and page 1 keeps "4444".
+	assertUsedBytes(t, s, 12)
+
+	// The purged page frees all its revisions.
+	if err := s.DeletePage(slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 12)
+	if err := s.PurgeTrash(storageOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 4)
+
+	// The purged slide frees the revisions of all pages.
+	if err := s.SetPage([]byte("666666"), slideId, pageIds[1], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 10)
+	if err := s.Delete(slideId, storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PurgeTrash(storageOp, 0); err != nil {
+		t.Fatal(err)
+	}
+	assertUsedBytes(t, s, 0)
+}
+
+func TestBytesQuotaFreesPrunedRevisions(t *testing.T) {
+	defer func(revisions int, bytes int64) {
+		maxRevisions = revisions
+		maxStorageBytes = bytes
+	}(maxRevisions, maxStorageBytes)
+	maxRevisions = 1
+	maxStorageBytes = 4
+
+	s, storageOp := newTestManager(t, "user")
+	slideId, pageIds := createTestSlide(t, s, 1)
+	if err := s.SetPage([]byte("1234"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	// The old revision is pruned, so the page can be overwritten at the limit.
+	if err := s.SetPage([]byte("abcd"), slideId, pageIds[0], storageOp); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPage([]byte("abcde"), slideId, pageIds[0], storageOp); err == nil {
+		t.Error("the page over the limit is written")
+	}
+	assertUsedBytes(t, s, 4)
+}
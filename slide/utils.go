@@ -1,17 +1,15 @@
 package slide
 
-import "fmt"
-
-// Pop element from list.
+// Remove element from list.
+// The order of the other elements is kept.
 func removeSlides(s []SlideContent, i int) []SlideContent {
-	s[i] = s[len(s)-1]
-	return s[:len(s)-1]
+	return append(s[:i], s[i+1:]...)
 }
 
-// Pop element from list.
+// Remove element from list.
+// The order of the other elements is kept.
 func removePage(s []PageData, i int) []PageData {
-	s[i] = s[len(s)-1]
-	return s[:len(s)-1]
+	return append(s[:i], s[i+1:]...)
 }
 
 // Returns the index of the corresponding slide ID.
@@ -33,7 +31,7 @@ func getIndexSlideConfig(slideConfig SlideConfig, targetId string) (int, error)
 		}
 	}
 	if !isExist {
-		return 0, fmt.Errorf("the specified slide ID does not exist")
+		return 0, ErrSlideNotFound
 	}
 	return targetIndex, nil
 }
@@ -42,7 +40,7 @@ func getIndexSlideConfig(slideConfig SlideConfig, targetId string) (int, error)
 //
 // Arguments:
 // - slideData: SlideData
-// - targetId: target slide id.
+// - targetId: target page id.
 //
 // Returns:
 // - int: Index of the corresponding targetId.
@@ -57,7 +55,64 @@ func getIndexPage(slideData SlideData, targetId string) (int, error) {
 		}
 	}
 	if !isExist {
-		return 0, fmt.Errorf("the specified slide ID does not exist")
+		return 0, ErrPageNotFound
 	}
 	return targetIndex, nil
 }
+
+// Swap the pages at origin and target.
+func swapPages(slideData *SlideData, origin int, target int) error {
+	if origin >= len(slideData.Pages) || target >= len(slideData.Pages) || origin < 0 || target < 0 {
+		return ErrIndexOutOfRange
+	}
+
+	buffer := slideData.Pages[origin]
+	slideData.Pages[origin] = slideData.Pages[target]
+	slideData.Pages[target] = buffer
+	return nil
+}
+
+// Move the page to newIndex.
+// The pages between the old and new index are shifted.
+func movePage(slideData *SlideData, pageId string, newIndex int) error {
+	if newIndex >= len(slideData.Pages) || newIndex < 0 {
+		return ErrIndexOutOfRange
+	}
+	oldIndex, err := getIndexPage(*slideData, pageId)
+	if err != nil {
+		return err
+	}
+
+	page := slideData.Pages[oldIndex]
+	if oldIndex < newIndex {
+		copy(slideData.Pages[oldIndex:newIndex], slideData.Pages[oldIndex+1:newIndex+1])
+	} else {
+		copy(slideData.Pages[newIndex+1:oldIndex+1], slideData.Pages[newIndex:oldIndex])
+	}
+	slideData.Pages[newIndex] = page
+	return nil
+}
+
+// Sort the pages in the order of pageIds.
+// pageIds must contain all pages exactly once.
+func orderPages(slideData *SlideData, pageIds []string) error {
+	pages := map[string]PageData{}
+	for _, page := range slideData.Pages {
+		pages[page.PageId] = page
+	}
+	if len(pageIds) != len(slideData.Pages) {
+		return InvalidInput("the order must contain all pages exactly once")
+	}
+
+	newPages := make([]PageData, 0, len(pageIds))
+	for _, pageId := range pageIds {
+		page, ok := pages[pageId]
+		if !ok {
+			return InvalidInput("the order must contain all pages exactly once")
+		}
+		delete(pages, pageId)
+		newPages = append(newPages, page)
+	}
+	slideData.Pages = newPages
+	return nil
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dapr/go-sdk/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type state struct {
+	client *client.Client
+	ctx    *context.Context
+	store  string
+}
+
+// Create state store operation handler of Dapr.
+func NewState(client *client.Client, ctx *context.Context, store string) StateStore {
+	return &state{
+		client: client,
+		ctx:    ctx,
+		store:  store,
+	}
+}
+
+func (s *state) Get(key string) (*Item, error) {
+	item, err := (*s.client).GetState(*s.ctx, s.store, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Item{
+		Key:   key,
+		Value: item.Value,
+		Etag:  item.Etag,
+	}, nil
+}
+
+func (s *state) BulkGet(keys []string) ([]*Item, error) {
+	items := make([]*Item, len(keys))
+	if len(keys) == 0 {
+		return items, nil
+	}
+	bulkItems, err := (*s.client).GetBulkState(*s.ctx, s.store, keys, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sidecar does not keep the order of keys.
+	indexes := map[string]int{}
+	for index, key := range keys {
+		indexes[key] = index
+		items[index] = &Item{Key: key}
+	}
+	for _, bulkItem := range bulkItems {
+		if len(bulkItem.Error) != 0 {
+			return nil, fmt.Errorf("get state %s: %s", bulkItem.Key, bulkItem.Error)
+		}
+		if index, ok := indexes[bulkItem.Key]; ok {
+			items[index].Value = bulkItem.Value
+			items[index].Etag = bulkItem.Etag
+		}
+	}
+	return items, nil
+}
+
+func (s *state) Set(key string, value []byte) error {
+	if err := (*s.client).SaveState(*s.ctx, s.store, key, value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *state) Delete(key string) error {
+	return (*s.client).DeleteState(*s.ctx, s.store, key)
+}
+
+func (s *state) SetWithETag(key string, value []byte, etag string) error {
+	item := &client.SetStateItem{
+		Key:   key,
+		Value: value,
+		Options: &client.StateOptions{
+			Concurrency: client.StateConcurrencyFirstWrite,
+			Consistency: client.StateConsistencyStrong,
+		},
+	}
+	if len(etag) != 0 {
+		item.Etag = &client.ETag{Value: etag}
+	}
+
+	return convertError((*s.client).SaveBulkState(*s.ctx, s.store, item))
+}
+
+func (s *state) DeleteWithETag(key string, etag string) error {
+	if len(etag) == 0 {
+		return s.Delete(key)
+	}
+	options := &client.StateOptions{
+		Concurrency: client.StateConcurrencyFirstWrite,
+		Consistency: client.StateConsistencyStrong,
+	}
+	err := (*s.client).DeleteStateWithETag(*s.ctx, s.store, key, &client.ETag{Value: etag}, nil, options)
+
+	return convertError(err)
+}
+
+func (s *state) Transaction(operations []Operation) error {
+	options := &client.StateOptions{
+		Concurrency: client.StateConcurrencyFirstWrite,
+		Consistency: client.StateConsistencyStrong,
+	}
+
+	ops := make([]*client.StateOperation, 0, len(operations))
+	for _, operation := range operations {
+		item := &client.SetStateItem{
+			Key:     operation.Key,
+			Value:   operation.Value,
+			Options: options,
+		}
+		if len(operation.Etag) != 0 {
+			item.Etag = &client.ETag{Value: operation.Etag}
+		}
+		if operation.TTL > 0 {
+			item.Metadata = map[string]string{
+				"ttlInSeconds": strconv.FormatInt(int64(math.Ceil(operation.TTL.Seconds())), 10),
+			}
+		}
+
+		opType := client.StateOperationTypeUpsert
+		if operation.Type == OperationDelete {
+			opType = client.StateOperationTypeDelete
+		}
+		ops = append(ops, &client.StateOperation{
+			Type: opType,
+			Item: item,
+		})
+	}
+
+	return convertError((*s.client).ExecuteStateTransaction(*s.ctx, s.store, nil, ops))
+}
+
+// Convert the etag error of the Dapr sidecar to ErrETagMismatch.
+func convertError(err error) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if s, ok := status.FromError(e); ok {
+			switch s.Code() {
+			case codes.Aborted, codes.FailedPrecondition:
+				return ErrETagMismatch
+			}
+		}
+	}
+	return err
+}
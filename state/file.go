@@ -0,0 +1,203 @@
+package state
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket of all keys in the database file.
+var fileBucket = []byte("state")
+
+// Each value is stored after the header of its 8 bytes version and 8 bytes expiration.
+// The version is the etag, and the expiration is unix time in nanoseconds or zero if it does not expire.
+const (
+	versionSize = 8
+	headerSize  = versionSize + 8
+)
+
+// Etag that is not checked. It is used by Set, and can not be an etag of a stored value.
+const anyETag = "*"
+
+type fileState struct {
+	db *bolt.DB
+	// Upserts with TTL since the last sweep. It is changed only in the write transactions.
+	expiring int
+}
+
+// Create state store on a BoltDB file `state.db` in dir.
+// Each operation is one BoltDB transaction, so a transaction is not applied partially when the process stops.
+// The file is locked, so only one process can use it.
+func NewFileState(dir string) (StateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(dir, "state.db"), 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(fileBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &fileState{
+		db: db,
+	}, nil
+}
+
+func (s *fileState) Get(key string) (*Item, error) {
+	item := &Item{Key: key}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		stored := liveValue(tx.Bucket(fileBucket).Get([]byte(key)), time.Now())
+		if stored == nil {
+			return nil
+		}
+		// The slice is valid only in the transaction.
+		item.Value = append([]byte(nil), stored[headerSize:]...)
+		item.Etag = fileETag(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *fileState) BulkGet(keys []string) ([]*Item, error) {
+	items := make([]*Item, len(keys))
+	now := time.Now()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(fileBucket)
+		for index, key := range keys {
+			items[index] = &Item{Key: key}
+			stored := liveValue(bucket.Get([]byte(key)), now)
+			if stored == nil {
+				continue
+			}
+			items[index].Value = append([]byte(nil), stored[headerSize:]...)
+			items[index].Etag = fileETag(stored)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *fileState) Set(key string, value []byte) error {
+	return s.Transaction([]Operation{
+		{Type: OperationUpsert, Key: key, Value: value, Etag: anyETag},
+	})
+}
+
+func (s *fileState) Delete(key string) error {
+	return s.Transaction([]Operation{
+		{Type: OperationDelete, Key: key},
+	})
+}
+
+func (s *fileState) SetWithETag(key string, value []byte, etag string) error {
+	return s.Transaction([]Operation{
+		{Type: OperationUpsert, Key: key, Value: value, Etag: etag},
+	})
+}
+
+func (s *fileState) DeleteWithETag(key string, etag string) error {
+	return s.Transaction([]Operation{
+		{Type: OperationDelete, Key: key, Etag: etag},
+	})
+}
+
+func (s *fileState) Transaction(operations []Operation) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(fileBucket)
+		now := time.Now()
+
+		for _, operation := range operations {
+			if operation.Etag == anyETag || (operation.Type == OperationDelete && len(operation.Etag) == 0) {
+				continue
+			}
+			if fileETag(liveValue(bucket.Get([]byte(operation.Key)), now)) != operation.Etag {
+				return ErrETagMismatch
+			}
+		}
+
+		for _, operation := range operations {
+			switch operation.Type {
+			case OperationUpsert:
+				version, err := bucket.NextSequence()
+				if err != nil {
+					return err
+				}
+				stored := make([]byte, headerSize+len(operation.Value))
+				binary.BigEndian.PutUint64(stored, version)
+				if operation.TTL > 0 {
+					binary.BigEndian.PutUint64(stored[versionSize:], uint64(now.Add(operation.TTL).UnixNano()))
+					s.expiring++
+				}
+				copy(stored[headerSize:], operation.Value)
+				if err := bucket.Put([]byte(operation.Key), stored); err != nil {
+					return err
+				}
+			case OperationDelete:
+				if err := bucket.Delete([]byte(operation.Key)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if s.expiring >= sweepInterval {
+			s.expiring = 0
+			return sweepFile(bucket, now)
+		}
+		return nil
+	})
+}
+
+// Delete the expired values.
+func sweepFile(bucket *bolt.Bucket, now time.Time) error {
+	// The cursor skips a key if the current one is deleted, so the keys are deleted after iterating.
+	keys := [][]byte{}
+	cursor := bucket.Cursor()
+	for key, stored := cursor.First(); key != nil; key, stored = cursor.Next() {
+		if liveValue(stored, now) == nil {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+	}
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the stored value, or nil if it does not exist or has expired.
+func liveValue(stored []byte, now time.Time) []byte {
+	if len(stored) < headerSize {
+		return nil
+	}
+	expires := binary.BigEndian.Uint64(stored[versionSize:])
+	if expires != 0 && now.UnixNano() >= int64(expires) {
+		return nil
+	}
+	return stored
+}
+
+// The etag of a stored value is its version.
+// A key that does not exist has an empty etag.
+func fileETag(stored []byte) string {
+	if len(stored) < headerSize {
+		return ""
+	}
+	return strconv.FormatUint(binary.BigEndian.Uint64(stored), 10)
+}
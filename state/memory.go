@@ -0,0 +1,169 @@
+package state
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Number of the upserts with TTL between the sweeps of the expired keys.
+const sweepInterval = 1000
+
+type memoryItem struct {
+	value   []byte
+	version uint64
+	// Zero if the item does not expire.
+	expires time.Time
+}
+
+type memoryState struct {
+	mu      sync.Mutex
+	items   map[string]memoryItem
+	version uint64
+	// Upserts with TTL since the last sweep.
+	expiring int
+}
+
+// Create in-memory state store.
+// Values are lost when the process exits.
+func NewMemoryState() StateStore {
+	return &memoryState{
+		items: map[string]memoryItem{},
+	}
+}
+
+func (s *memoryState) Get(key string) (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.lookup(key)
+	if !ok {
+		return &Item{Key: key}, nil
+	}
+	return &Item{
+		Key:   key,
+		Value: append([]byte(nil), item.value...),
+		Etag:  strconv.FormatUint(item.version, 10),
+	}, nil
+}
+
+func (s *memoryState) BulkGet(keys []string) ([]*Item, error) {
+	items := make([]*Item, 0, len(keys))
+	for _, key := range keys {
+		item, err := s.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *memoryState) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, 0)
+	return nil
+}
+
+func (s *memoryState) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+func (s *memoryState) SetWithETag(key string, value []byte, etag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.match(key, etag) {
+		return ErrETagMismatch
+	}
+	s.set(key, value, 0)
+	return nil
+}
+
+func (s *memoryState) DeleteWithETag(key string, etag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(etag) != 0 && !s.match(key, etag) {
+		return ErrETagMismatch
+	}
+	delete(s.items, key)
+	return nil
+}
+
+func (s *memoryState) Transaction(operations []Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, operation := range operations {
+		if operation.Type == OperationDelete && len(operation.Etag) == 0 {
+			continue
+		}
+		if !s.match(operation.Key, operation.Etag) {
+			return ErrETagMismatch
+		}
+	}
+
+	for _, operation := range operations {
+		switch operation.Type {
+		case OperationUpsert:
+			s.set(operation.Key, operation.Value, operation.TTL)
+		case OperationDelete:
+			delete(s.items, operation.Key)
+		}
+	}
+	return nil
+}
+
+func (s *memoryState) set(key string, value []byte, ttl time.Duration) {
+	s.version++
+	item := memoryItem{
+		value:   append([]byte(nil), value...),
+		version: s.version,
+	}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+		s.expiring++
+		if s.expiring >= sweepInterval {
+			s.sweep()
+		}
+	}
+	s.items[key] = item
+}
+
+// Returns the item of key unless it has expired.
+func (s *memoryState) lookup(key string) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if ok && !item.expires.IsZero() && !time.Now().Before(item.expires) {
+		delete(s.items, key)
+		return memoryItem{}, false
+	}
+	return item, ok
+}
+
+// Delete the expired items.
+func (s *memoryState) sweep() {
+	now := time.Now()
+	for key, item := range s.items {
+		if !item.expires.IsZero() && !now.Before(item.expires) {
+			delete(s.items, key)
+		}
+	}
+	s.expiring = 0
+}
+
+// Check if the stored etag of key equals etag.
+// An empty etag matches only a key that does not exist.
+func (s *memoryState) match(key string, etag string) bool {
+	item, ok := s.lookup(key)
+	if !ok {
+		return len(etag) == 0
+	}
+	return strconv.FormatUint(item.version, 10) == etag
+}
@@ -1,37 +1,66 @@
 package state
 
 import (
-	"context"
-
-	"github.com/dapr/go-sdk/client"
+	"errors"
+	"time"
 )
 
-type state struct {
-	client *client.Client
-	ctx    *context.Context
-	store  string
-}
+// The stored etag does not match the expected one.
+var ErrETagMismatch = errors.New("state etag mismatch")
 
-func NewState(client *client.Client, ctx *context.Context, store string) *state {
-	return &state{
-		client: client,
-		ctx:    ctx,
-		store:  store,
-	}
-}
+// Key-value store of the state documents.
+type StateStore interface {
+	// Get the item of key.
+	// If the key does not exist, Value is empty.
+	Get(key string) (*Item, error)
+
+	// Get the items of keys in the same order as keys.
+	// If a key does not exist, its Value is empty.
+	BulkGet(keys []string) ([]*Item, error)
+
+	// Set value to key.
+	Set(key string, value []byte) error
 
-func (s *state) Get(key string) (*client.StateItem, error) {
-	return (*s.client).GetState(*s.ctx, s.store, key)
+	// Delete key.
+	Delete(key string) error
+
+	// Set value to key only if the stored etag matches.
+	// If etag is empty, the key must not exist.
+	// Returns ErrETagMismatch if another writer has changed the key.
+	SetWithETag(key string, value []byte, etag string) error
+
+	// Delete key only if the stored etag matches.
+	// Returns ErrETagMismatch if another writer has changed the key.
+	DeleteWithETag(key string, etag string) error
+
+	// Apply all operations atomically.
+	// Each etag is checked in the same way as SetWithETag and DeleteWithETag,
+	// and nothing is applied if any of them does not match.
+	Transaction(operations []Operation) error
 }
 
-func (s *state) Set(key string, value []byte) error {
-	if err := (*s.client).SaveState(*s.ctx, s.store, key, value); err != nil {
-		return err
-	}
+type OperationType int
+
+const (
+	// Set the value of key.
+	OperationUpsert OperationType = iota
+	// Delete key.
+	OperationDelete
+)
 
-	return nil
+// Operation of the transaction.
+type Operation struct {
+	Type  OperationType
+	Key   string
+	Value []byte
+	Etag  string
+	// The upserted key is deleted after TTL. It does not expire if zero.
+	TTL time.Duration
 }
 
-func (s *state) Delete(key string) error {
-	return (*s.client).DeleteState(*s.ctx, s.store, key)
+// Stored value.
+type Item struct {
+	Key   string
+	Value []byte
+	Etag  string
 }
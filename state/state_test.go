@@ -0,0 +1,249 @@
+package state
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Backends tested with the same cases.
+func stateStores(t *testing.T) map[string]StateStore {
+	fileState, err := NewFileState(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]StateStore{
+		"memory": NewMemoryState(),
+		"file":   fileState,
+	}
+}
+
+func TestGetMissingKey(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			item, err := store.Get("missing")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(item.Value) != 0 || len(item.Etag) != 0 {
+				t.Errorf("got %q with etag %q, want empty", item.Value, item.Etag)
+			}
+		})
+	}
+}
+
+func TestSetWithETag(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.SetWithETag("key", []byte("1"), ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SetWithETag("key", []byte("2"), ""); !errors.Is(err, ErrETagMismatch) {
+				t.Fatalf("create existing key: got %v, want ErrETagMismatch", err)
+			}
+
+			item, err := store.Get("key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SetWithETag("key", []byte("3"), item.Etag); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SetWithETag("key", []byte("4"), item.Etag); !errors.Is(err, ErrETagMismatch) {
+				t.Fatalf("stale etag: got %v, want ErrETagMismatch", err)
+			}
+
+			item, err = store.Get("key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(item.Value) != "3" {
+				t.Errorf("got %q, want %q", item.Value, "3")
+			}
+		})
+	}
+}
+
+func TestBulkGet(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set("a", []byte("1")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("b", []byte("2")); err != nil {
+				t.Fatal(err)
+			}
+			item, err := store.Get("b")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, err := store.BulkGet([]string{"b", "missing", "a"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, bulkItem := range items {
+				got = append(got, bulkItem.Key+"="+string(bulkItem.Value))
+			}
+			if strings.Join(got, ",") != "b=2,missing=,a=1" {
+				t.Errorf("got %v, want [b=2 missing= a=1]", got)
+			}
+			if items[0].Etag != item.Etag {
+				t.Errorf("etag: got %q, want %q", items[0].Etag, item.Etag)
+			}
+		})
+	}
+}
+
+func TestDeleteWithETag(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set("key", []byte("1")); err != nil {
+				t.Fatal(err)
+			}
+			item, err := store.Get("key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("key", []byte("2")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.DeleteWithETag("key", item.Etag); !errors.Is(err, ErrETagMismatch) {
+				t.Fatalf("stale etag: got %v, want ErrETagMismatch", err)
+			}
+
+			item, err = store.Get("key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.DeleteWithETag("key", item.Etag); err != nil {
+				t.Fatal(err)
+			}
+			item, err = store.Get("key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(item.Value) != 0 {
+				t.Errorf("got %q after delete, want empty", item.Value)
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set("a", []byte("a1")); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("b", []byte("b1")); err != nil {
+				t.Fatal(err)
+			}
+			a, err := store.Get("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Nothing is applied if any etag does not match.
+			err = store.Transaction([]Operation{
+				{Type: OperationUpsert, Key: "a", Value: []byte("a2"), Etag: a.Etag},
+				{Type: OperationDelete, Key: "b", Etag: "stale"},
+			})
+			if !errors.Is(err, ErrETagMismatch) {
+				t.Fatalf("got %v, want ErrETagMismatch", err)
+			}
+			assertValue(t, store, "a", "a1")
+			assertValue(t, store, "b", "b1")
+
+			b, err := store.Get("b")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.Transaction([]Operation{
+				{Type: OperationUpsert, Key: "a", Value: []byte("a2"), Etag: a.Etag},
+				{Type: OperationDelete, Key: "b", Etag: b.Etag},
+				{Type: OperationUpsert, Key: "c", Value: []byte("c1")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertValue(t, store, "a", "a2")
+			assertValue(t, store, "b", "")
+			assertValue(t, store, "c", "c1")
+		})
+	}
+}
+
+func TestTransactionTTL(t *testing.T) {
+	for name, store := range stateStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := store.Transaction([]Operation{
+				{Type: OperationUpsert, Key: "expiring", Value: []byte("1"), TTL: 50 * time.Millisecond},
+				{Type: OperationUpsert, Key: "kept", Value: []byte("2")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertValue(t, store, "expiring", "1")
+
+			time.Sleep(100 * time.Millisecond)
+			item, err := store.Get("expiring")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(item.Value) != 0 || len(item.Etag) != 0 {
+				t.Errorf("expired key: got %q with etag %q, want empty", item.Value, item.Etag)
+			}
+			assertValue(t, store, "kept", "2")
+
+			// The expired key can be created again.
+			if err := store.SetWithETag("expiring", []byte("3"), ""); err != nil {
+				t.Fatal(err)
+			}
+			assertValue(t, store, "expiring", "3")
+		})
+	}
+}
+
+func TestFileStateReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("key", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	item, err := store.Get("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.(*fileState).db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopenedItem, err := reopened.Get("key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(reopenedItem.Value) != "value" || reopenedItem.Etag != item.Etag {
+		t.Errorf("got %q with etag %q, want %q with etag %q", reopenedItem.Value, reopenedItem.Etag, "value", item.Etag)
+	}
+}
+
+func assertValue(t *testing.T, store StateStore, key string, want string) {
+	t.Helper()
+	item, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Value) != want {
+		t.Errorf("%s: got %q, want %q", key, item.Value, want)
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// Errors of the blob store.
+var (
+	ErrNotExist        = errors.New("the file does not exist")
+	ErrVersionMismatch = errors.New("the file has been changed")
+	ErrInvalidPath     = errors.New("invalid file path")
+)
+
+// Attributes of the file.
+type FileInfo struct {
+	Size int64
+	// Changed whenever the file is written, such as the generation of Google Cloud Storage.
+	Version string
+	ModTime time.Time
+}
+
+// Storage of the page data.
+// A file is addressed by its directories and file name, and it is joined with `/`.
+type BlobStore interface {
+	// Write file.
+	WriteFile(dirs []string, fileName string, body []byte) error
+
+	// Write file from body without buffering it entirely.
+	// The file is not saved if reading body fails.
+	// Returns the written size.
+	WriteStream(dirs []string, fileName string, body io.Reader) (int64, error)
+
+	// Read file.
+	ReadFile(dirs []string, fileName string) ([]byte, error)
+
+	// Open file to read it as a stream.
+	// Returns the reader and the attributes of the file.
+	OpenFile(dirs []string, fileName string) (io.ReadCloser, *FileInfo, error)
+
+	// Returns the attributes of the file.
+	// Returns ErrNotExist if the file does not exist.
+	Stat(dirs []string, fileName string) (*FileInfo, error)
+
+	// Check if file exists.
+	FileExist(dirs []string, fileName string) (bool, error)
+
+	// Delete all files whose path starts with prefix.
+	Delete(prefix string) error
+
+	// List the path of files whose path starts with prefix.
+	List(prefix string) ([]string, error)
+
+	// Copy file in the storage without reading it to the service.
+	Copy(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string) error
+
+	// Copy file only if the version of the destination is version.
+	// The empty version means that the destination must not exist.
+	// Returns ErrVersionMismatch if the destination has been changed.
+	CopyIfMatch(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string, version string) error
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Lock to check the version and write the file at once.
+var localWriteMutex sync.Mutex
+
+type LocalStorageOp struct {
+	root string
+}
+
+// Create local directory storage operation handler.
+// The file `pages/<user>/<slide>/<page>` is saved as `<root>/pages/<user>/<slide>/<page>`.
+func NewLocalStorageOp(root string) (*LocalStorageOp, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorageOp{
+		root: root,
+	}, nil
+}
+
+// Returns the file path on disk.
+// Each of dirs and fileName must be one path element so that the path does not go out of the root.
+func (s *LocalStorageOp) filePath(dirs []string, fileName string) (string, error) {
+	elements := []string{s.root}
+	for _, element := range append(append([]string{}, dirs...), fileName) {
+		if !isPathElement(element) {
+			return "", ErrInvalidPath
+		}
+		elements = append(elements, element)
+	}
+	return filepath.Join(elements...), nil
+}
+
+// Returns true if element is a file or directory name, not `..` or a path.
+func isPathElement(element string) bool {
+	return len(element) != 0 && element != "." && element != ".." && !strings.ContainsAny(element, "/\\\x00")
+}
+
+// Returns the directory on disk that contains the files of prefix.
+// The prefix such as `pages/<user>/<slide>` must not have `..`.
+func (s *LocalStorageOp) prefixDir(prefix string) (string, error) {
+	if strings.ContainsAny(prefix, "\\\x00") {
+		return "", ErrInvalidPath
+	}
+	for _, element := range strings.Split(prefix, "/") {
+		if element == "." || element == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+	return filepath.Join(s.root, filepath.FromSlash(path.Dir(prefix))), nil
+}
+
+// Check if file exists.
+// Exist if true, false not.
+func (s *LocalStorageOp) FileExist(dirs []string, fileName string) (bool, error) {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Read file.
+func (s *LocalStorageOp) ReadFile(dirs []string, fileName string) ([]byte, error) {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filePath)
+}
+
+// Write file
+func (s *LocalStorageOp) WriteFile(dirs []string, fileName string, body []byte) error {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, body, 0o644)
+}
+
+// Write file from reader
+// It is written to a temporary file and renamed so that a failed write does not leave the file.
+func (s *LocalStorageOp) WriteStream(dirs []string, fileName string, body io.Reader) (int64, error) {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return 0, err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(filePath), "."+fileName+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+
+	size, err := io.Copy(file, body)
+	if err != nil {
+		file.Close()
+		return size, err
+	}
+	if err := file.Close(); err != nil {
+		return size, err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return size, err
+	}
+	return size, os.Rename(file.Name(), filePath)
+}
+
+// Returns the file attributes.
+// The version is made of the modification time and the size because the file is replaced by renaming when it is written.
+func localFileInfo(info os.FileInfo) *FileInfo {
+	return &FileInfo{
+		Size:    info.Size(),
+		Version: fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		ModTime: info.ModTime(),
+	}
+}
+
+// Open file
+func (s *LocalStorageOp) OpenFile(dirs []string, fileName string) (io.ReadCloser, *FileInfo, error) {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, localFileInfo(info), nil
+}
+
+// Get file attributes
+func (s *LocalStorageOp) Stat(dirs []string, fileName string) (*FileInfo, error) {
+	filePath, err := s.filePath(dirs, fileName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return localFileInfo(info), nil
+}
+
+// Copy file
+func (s *LocalStorageOp) Copy(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string) error {
+	reader, _, err := s.OpenFile(srcDirs, srcFileName)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = s.WriteStream(dstDirs, dstFileName, reader)
+	return err
+}
+
+// Copy file if the version of the destination matches
+// It is only atomic within this process.
+func (s *LocalStorageOp) CopyIfMatch(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string, version string) error {
+	localWriteMutex.Lock()
+	defer localWriteMutex.Unlock()
+
+	info, err := s.Stat(dstDirs, dstFileName)
+	if err != nil && err != ErrNotExist {
+		return err
+	}
+	if (info == nil && len(version) != 0) || (info != nil && info.Version != version) {
+		return ErrVersionMismatch
+	}
+	return s.Copy(srcDirs, srcFileName, dstDirs, dstFileName)
+}
+
+// Delete files
+func (s *LocalStorageOp) Delete(prefix string) error {
+	names, err := s.List(prefix)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(s.root, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// List files
+func (s *LocalStorageOp) List(prefix string) ([]string, error) {
+	names := []string{}
+
+	// Walk only the directory containing the prefix.
+	dir, err := s.prefixDir(prefix)
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorageRejectsPathOutOfRoot(t *testing.T) {
+	root := t.TempDir()
+	storageOp, err := NewLocalStorageOp(filepath.Join(root, "pages-root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file next to the storage root.
+	if err := ioutil.WriteFile(filepath.Join(root, "secret"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		dirs     []string
+		fileName string
+	}{
+		{name: "parent file name", dirs: []string{"pages", "user", "slide"}, fileName: "../../../../secret"},
+		{name: "dot dot", dirs: []string{"pages", "user", "slide"}, fileName: ".."},
+		{name: "parent dir", dirs: []string{"pages", "..", ".."}, fileName: "secret"},
+		{name: "separator", dirs: []string{"pages", "user/other"}, fileName: "page"},
+		{name: "backslash", dirs: []string{"pages", "user"}, fileName: `..\secret`},
+		{name: "empty", dirs: []string{"pages", ""}, fileName: "page"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := storageOp.ReadFile(c.dirs, c.fileName); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("ReadFile: got %v, want ErrInvalidPath", err)
+			}
+			if _, _, err := storageOp.OpenFile(c.dirs, c.fileName); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("OpenFile: got %v, want ErrInvalidPath", err)
+			}
+			if _, err := storageOp.WriteStream(c.dirs, c.fileName, bytes.NewReader([]byte("data"))); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("WriteStream: got %v, want ErrInvalidPath", err)
+			}
+		})
+	}
+
+	if _, err := storageOp.List("pages/../../secret"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("List: got %v, want ErrInvalidPath", err)
+	}
+	if err := storageOp.Delete("../secret"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Delete: got %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestLocalStorageReadWrite(t *testing.T) {
+	storageOp, err := NewLocalStorageOp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := []string{"pages", "user", "slide"}
+	if _, err := storageOp.WriteStream(dirs, "page", bytes.NewReader([]byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+	data, err := storageOp.ReadFile(dirs, "page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "data" {
+		t.Errorf("got %q, want %q", data, "data")
+	}
+	names, err := storageOp.List("pages/user/slide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "pages/user/slide/page" {
+		t.Errorf("got %v, want [pages/user/slide/page]", names)
+	}
+}
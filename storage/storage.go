@@ -2,10 +2,15 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -82,6 +87,98 @@ func (s *StorageOp) WriteFile(dirs []string, fileName string, body []byte) error
 	return nil
 }
 
+// Write file from reader
+// The upload is canceled without saving the object if reading body fails.
+func (s *StorageOp) WriteStream(dirs []string, fileName string, body io.Reader) (int64, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	object := s.Object(dirs, fileName)
+	writer := object.NewWriter(ctx)
+
+	size, err := io.Copy(writer, body)
+	if err != nil {
+		cancel()
+		writer.Close()
+		return size, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return size, err
+	}
+	return size, nil
+}
+
+// Open file
+func (s *StorageOp) OpenFile(dirs []string, fileName string) (io.ReadCloser, *FileInfo, error) {
+	object := s.Object(dirs, fileName)
+	reader, err := object.NewReader(s.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, &FileInfo{
+		Size:    reader.Attrs.Size,
+		Version: strconv.FormatInt(reader.Attrs.Generation, 10),
+		ModTime: reader.Attrs.LastModified,
+	}, nil
+}
+
+// Get file attributes
+func (s *StorageOp) Stat(dirs []string, fileName string) (*FileInfo, error) {
+	object := s.Object(dirs, fileName)
+	attrs, err := object.Attrs(s.ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &FileInfo{
+		Size:    attrs.Size,
+		Version: strconv.FormatInt(attrs.Generation, 10),
+		ModTime: attrs.Updated,
+	}, nil
+}
+
+// Copy file
+// It is copied in Google Cloud Storage.
+func (s *StorageOp) Copy(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string) error {
+	src := s.Object(srcDirs, srcFileName)
+	dst := s.Object(dstDirs, dstFileName)
+
+	if _, err := dst.CopierFrom(src).Run(s.ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Copy file if the generation of the destination matches
+// The condition is checked by Google Cloud Storage atomically.
+func (s *StorageOp) CopyIfMatch(srcDirs []string, srcFileName string, dstDirs []string, dstFileName string, version string) error {
+	conditions := storage.Conditions{
+		DoesNotExist: true,
+	}
+	if len(version) != 0 {
+		generation, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return ErrVersionMismatch
+		}
+		conditions = storage.Conditions{
+			GenerationMatch: generation,
+		}
+	}
+
+	src := s.Object(srcDirs, srcFileName)
+	dst := s.Object(dstDirs, dstFileName).If(conditions)
+
+	_, err := dst.CopierFrom(src).Run(s.ctx)
+	var apiError *googleapi.Error
+	if errors.As(err, &apiError) && apiError.Code == http.StatusPreconditionFailed {
+		return ErrVersionMismatch
+	}
+	return err
+}
+
 // Delete files
 func (s *StorageOp) Delete(prefix string) error {
 	objects := s.rc.Objects(s.ctx, &storage.Query{
@@ -103,6 +200,26 @@ func (s *StorageOp) Delete(prefix string) error {
 	return nil
 }
 
+// List files
+func (s *StorageOp) List(prefix string) ([]string, error) {
+	objects := s.rc.Objects(s.ctx, &storage.Query{
+		Prefix: prefix,
+	})
+
+	names := []string{}
+	for {
+		attrs, err := objects.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+	return names, nil
+}
+
 // disable to versioning.
 func (s *StorageOp) DisableVersioning() error {
 	bucketAttrsToUpdate := storage.BucketAttrsToUpdate{